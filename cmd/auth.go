@@ -0,0 +1,441 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/cache"
+	"harvest-cli/internal/config"
+	"harvest-cli/internal/output"
+	"harvest-cli/internal/ui"
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Log in to Harvest and manage the stored credentials",
+	Long: `login, logout and check which Harvest account the CLI uses.
+
+The token is looked up in HARVEST_TOKEN or the config file first, then in
+the output of token_command (e.g. "pass show harvest"), and last in the
+credentials stored by 'harvest auth login'.`,
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in with a personal access token or in the browser",
+	Long: `Prompt for a personal access token and account ID, created at
+https://id.getharvest.com/developers, check them against Harvest and store
+them in a file only you can read.
+
+When the config sets up an OAuth2 application with oauth_client_id and
+oauth_client_secret, log in in the browser instead and pick one of your
+accounts. The access token is refreshed automatically once it expires.
+
+With --encrypt, the tokens are encrypted with a passphrase asked for when
+they are used, or read from HARVEST_PASSPHRASE.`,
+	Args: cobra.NoArgs,
+	RunE: runAuthLogin,
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored credentials",
+	Args:  cobra.NoArgs,
+	RunE:  runAuthLogout,
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the user and account you are logged in as",
+	Args:  cobra.NoArgs,
+	RunE:  runAuthStatus,
+}
+
+var (
+	authEncrypt   bool
+	authOAuth     bool
+	authAccountId string
+)
+
+func init() {
+	authCmd.AddCommand(authLoginCmd)
+	authCmd.AddCommand(authLogoutCmd)
+	authCmd.AddCommand(authStatusCmd)
+
+	addGlobalFlags(authCmd)
+
+	authLoginCmd.Flags().BoolVar(&authEncrypt, "encrypt", false, "Encrypt the stored token with a passphrase")
+	authLoginCmd.Flags().BoolVar(&authOAuth, "oauth", false, "Log in in the browser with the OAuth2 application of the config (default when one is set)")
+	authLoginCmd.Flags().StringVar(&authAccountId, "account-id", "", "Account to log in to, instead of choosing it")
+}
+
+func runAuthLogin(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(profileName)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// An OAuth2 application set up in the config is used unless --oauth=false
+	useOAuth := authOAuth || (cfg.OAuthClientId != "" && !cmd.Flags().Changed("oauth"))
+
+	var credentials *config.Credentials
+	var user *api.User
+	if useOAuth {
+		credentials, user, err = loginOAuth(cmd, cfg)
+	} else {
+		credentials, user, err = loginToken(cmd, cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	passphrase := ""
+	if authEncrypt {
+		switch {
+		case canPrompt():
+			passphrase, err = promptNewPassphrase()
+			if err != nil {
+				return err
+			}
+		case cfg.Passphrase != "":
+			passphrase = cfg.Passphrase
+		default:
+			return fmt.Errorf("--encrypt prompts for the passphrase and needs a terminal, set HARVEST_PASSPHRASE instead")
+		}
+	}
+	if err := storeCredentials(cfg.Profile, credentials, passphrase); err != nil {
+		return err
+	}
+
+	fmt.Printf("Logged in as %s on account %s%s.\n", user.Name, credentials.AccountId, profileSuffix(cfg.Profile))
+
+	path, _ := config.CredentialsPath(cfg.Profile)
+	if cfg.TokenSource != "" && cfg.TokenSource != path {
+		fmt.Printf("The token from %s still takes precedence over the stored one.\n", cfg.TokenSource)
+	}
+
+	return nil
+}
+
+// loginToken prompts for a personal access token and checks it
+func loginToken(cmd *cobra.Command, cfg *config.Config) (*config.Credentials, *api.User, error) {
+	if !canPrompt() {
+		return nil, nil, fmt.Errorf("auth login prompts for the token and needs a terminal, set HARVEST_TOKEN and HARVEST_ACCOUNT_ID instead")
+	}
+
+	token, err := ui.PasswordInput("Harvest login", "Personal access token: ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to read token: %w", err)
+	}
+	accountId := authAccountId
+	if accountId == "" {
+		accountId, err = ui.TextInput(ui.TextInputOptions{
+			Title:        "Harvest login",
+			Prompt:       "Account ID: ",
+			Required:     true,
+			DefaultValue: cfg.AccountId,
+			ValidateFunc: validateAccountId,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to read account ID: %w", err)
+		}
+	}
+
+	client, err := api.NewClient(token, accountId, api.ClientOptions{
+		BaseURL: cfg.BaseURL,
+		Timeout: time.Duration(timeout) * time.Second,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := client.GetMe(cmd.Context())
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to verify credentials: %w", err)
+	}
+
+	return &config.Credentials{AccountId: accountId, UserId: user.ID, Token: token}, user, nil
+}
+
+// loginOAuth lets the user authorize the OAuth2 application in the browser
+// and choose one of their Harvest accounts
+func loginOAuth(cmd *cobra.Command, cfg *config.Config) (*config.Credentials, *api.User, error) {
+	oauth, err := newOAuth(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx := cmd.Context()
+	token, err := oauth.Authorize(ctx, func(page string) error {
+		fmt.Printf("Open this page to log in to Harvest:\n  %s\n", page)
+		if err := openBrowser(page); err != nil && verbose {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Could not open the browser: %v\n", err)
+		}
+		fmt.Println("Waiting for the authorization...")
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to log in: %w", err)
+	}
+
+	accounts, err := oauth.Accounts(ctx, token.AccessToken)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to list accounts: %w", err)
+	}
+	account, err := chooseAccount(cmd, accounts.HarvestAccounts())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &config.Credentials{
+		AccountId:    strconv.FormatInt(account.ID, 10),
+		UserId:       accounts.User.ID,
+		Token:        token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresAt:    token.ExpiresAt(),
+	}, &accounts.User, nil
+}
+
+// chooseAccount returns the account given with --account-id, the only one
+// or the one the user picks
+func chooseAccount(cmd *cobra.Command, accounts []api.Account) (*api.Account, error) {
+	if authAccountId != "" {
+		for _, account := range accounts {
+			if strconv.FormatInt(account.ID, 10) == authAccountId {
+				return &account, nil
+			}
+		}
+		return nil, &usageError{err: fmt.Errorf("--account-id %s is not one of your Harvest accounts", authAccountId), cmd: cmd}
+	}
+
+	switch {
+	case len(accounts) == 0:
+		return nil, fmt.Errorf("You have no Harvest account")
+	case len(accounts) == 1:
+		return &accounts[0], nil
+	case !canPrompt():
+		var b strings.Builder
+		fmt.Fprintf(&b, "You have %d Harvest accounts, choose one with --account-id:", len(accounts))
+		for _, account := range accounts {
+			fmt.Fprintf(&b, "\n  %-10d %s", account.ID, account.Name)
+		}
+		return nil, &usageError{err: fmt.Errorf("%s", b.String()), cmd: cmd}
+	}
+
+	account, err := ui.SelectAccount(cmd.Context(), accounts)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to select account: %w", err)
+	}
+	return account, nil
+}
+
+// newOAuth creates the OAuth2 client of the application in the config
+func newOAuth(cfg *config.Config) (*api.OAuth, error) {
+	return api.NewOAuth(cfg.OAuthClientId, cfg.OAuthClientSecret, api.OAuthOptions{
+		IdentityURL:  cfg.IdentityURL,
+		RedirectPort: cfg.OAuthRedirectPort,
+		Timeout:      time.Duration(timeout) * time.Second,
+	})
+}
+
+// refreshCredentials returns the function renewing the stored OAuth2
+// tokens when Harvest rejects the access token
+func refreshCredentials(cfg *config.Config) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		oauth, err := newOAuth(cfg)
+		if err != nil {
+			return "", err
+		}
+
+		credentials := cfg.Credentials
+		token, err := oauth.Refresh(ctx, credentials.RefreshToken)
+		if err != nil {
+			return "", err
+		}
+		credentials.Token = token.AccessToken
+		credentials.RefreshToken = token.RefreshToken
+		credentials.ExpiresAt = token.ExpiresAt()
+
+		// The refresh token was used up, so losing the new one logs the user out
+		passphrase := ""
+		if credentials.Sealed != nil {
+			passphrase = cfg.Passphrase
+		}
+		if err := storeCredentials(cfg.Profile, credentials, passphrase); err != nil {
+			return "", err
+		}
+		return token.AccessToken, nil
+	}
+}
+
+// storeCredentials saves the credentials of a profile, sealed when a
+// passphrase is given
+func storeCredentials(profile string, credentials *config.Credentials, passphrase string) error {
+	stored := credentials
+	if passphrase != "" {
+		sealed, err := credentials.Seal(passphrase)
+		if err != nil {
+			return fmt.Errorf("Failed to encrypt credentials: %w", err)
+		}
+		stored = sealed
+	}
+
+	if err := config.SaveCredentials(profile, stored); err != nil {
+		return fmt.Errorf("Failed to store credentials: %w", err)
+	}
+	return nil
+}
+
+// openBrowser opens the page in the user's browser, $BROWSER when set
+var openBrowser = func(page string) error {
+	var cmd *exec.Cmd
+	switch browser := os.Getenv("BROWSER"); {
+	case browser != "":
+		cmd = exec.Command(browser, page)
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("open", page)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", page)
+	default:
+		cmd = exec.Command("xdg-open", page)
+	}
+	return cmd.Start()
+}
+
+// validateAccountId checks that the account ID is a number, as shown on
+// the developers page
+func validateAccountId(value string) error {
+	if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+		return fmt.Errorf("the account ID is a number")
+	}
+	return nil
+}
+
+// promptNewPassphrase asks for the passphrase encrypting the token twice
+func promptNewPassphrase() (string, error) {
+	passphrase, err := ui.PasswordInput("Encrypt credentials", "Passphrase: ")
+	if err != nil {
+		return "", fmt.Errorf("Failed to read passphrase: %w", err)
+	}
+	// An empty passphrase would store the token in plain text
+	if passphrase == "" {
+		return "", fmt.Errorf("The passphrase cannot be empty")
+	}
+	repeated, err := ui.PasswordInput("Encrypt credentials", "Repeat passphrase: ")
+	if err != nil {
+		return "", fmt.Errorf("Failed to read passphrase: %w", err)
+	}
+	if passphrase != repeated {
+		return "", fmt.Errorf("The passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+func runAuthLogout(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(profileName)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	credentials, err := config.LoadCredentials(cfg.Profile)
+	if err != nil {
+		return fmt.Errorf("Failed to log out: %w", err)
+	}
+	removed, err := config.RemoveCredentials(cfg.Profile)
+	if err != nil {
+		return fmt.Errorf("Failed to log out: %w", err)
+	}
+	if !removed {
+		fmt.Println("No stored credentials.")
+		return nil
+	}
+
+	// The cache of the user logging out, leaving the other profiles' alone.
+	// Credentials stored before the user was recorded are looked up by token.
+	userId := credentials.UserId
+	if userId == 0 && credentials.Token != "" {
+		userId, _ = cache.LookupUser(credentials.AccountId, credentials.Token)
+	}
+	if userId != 0 {
+		if err := cache.ClearUser(credentials.AccountId, userId); err != nil {
+			return fmt.Errorf("Failed to clear the cache: %w", err)
+		}
+	}
+
+	fmt.Println("Logged out.")
+
+	return nil
+}
+
+func runAuthStatus(cmd *cobra.Command, args []string) error {
+	if _, err := output.NewRenderer(outputFormat); err != nil {
+		return &usageError{err: err, cmd: cmd}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	client, err := newAPIClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	user, err := client.GetMe(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("Failed to verify credentials: %w", err)
+	}
+
+	status := authStatus{
+		Profile:     cfg.Profile,
+		User:        user.Name,
+		Email:       user.Email,
+		AccountId:   cfg.AccountId,
+		TokenSource: cfg.TokenSource,
+	}
+	if path, _ := config.CredentialsPath(cfg.Profile); cfg.TokenSource == path {
+		status.Encrypted = cfg.Credentials.Sealed != nil
+		status.OAuth = cfg.Credentials.RefreshToken != ""
+	}
+	if company, err := client.GetCompany(cmd.Context()); err == nil {
+		status.Account = company.Name
+	}
+
+	return render(cmd, status)
+}
+
+// authStatus describes the credentials in use
+type authStatus struct {
+	Profile     string `json:"profile,omitempty"`
+	User        string `json:"user"`
+	Email       string `json:"email,omitempty"`
+	Account     string `json:"account,omitempty"`
+	AccountId   string `json:"account_id"`
+	TokenSource string `json:"token_source"`
+	Encrypted   bool   `json:"encrypted"`
+	OAuth       bool   `json:"oauth"`
+}
+
+func (s authStatus) Table() output.Table {
+	return output.Table{
+		Headers: []string{"Field", "Value"},
+		Rows: [][]string{
+			{"Profile", cmp.Or(s.Profile, config.DefaultProfile)},
+			{"User", s.User},
+			{"Email", s.Email},
+			{"Account", s.Account},
+			{"Account ID", s.AccountId},
+			{"Token from", s.TokenSource},
+			{"Encrypted", strconv.FormatBool(s.Encrypted)},
+			{"OAuth2", strconv.FormatBool(s.OAuth)},
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/cache"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of your project assignments",
+	Long: `refresh or clear the projects and tasks cached for the selectors, name
+resolution and shell completion.
+
+Cached assignments are used for an hour, then only the changes are fetched,
+and everything is fetched again once a week.`,
+}
+
+var cacheRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Fetch every project assignment again",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheRefresh,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every cached file",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheClear,
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheRefreshCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+
+	addGlobalFlags(cacheCmd)
+}
+
+func runCacheRefresh(cmd *cobra.Command, args []string) error {
+	if _, err := createAPIClient(); err != nil {
+		return err
+	}
+	if assignmentCache == nil {
+		_, err := cache.Dir()
+		return err
+	}
+
+	assignments, err := assignmentCache.Refresh(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("Failed to list projects: %w", err)
+	}
+
+	fmt.Printf("Cached %d project assignments.\n", len(assignments))
+
+	return nil
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	if err := cache.Clear(); err != nil {
+		return fmt.Errorf("Failed to clear the cache: %w", err)
+	}
+
+	fmt.Println("Cache cleared.")
+
+	return nil
+}
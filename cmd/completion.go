@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/resolve"
+)
+
+// addAssignmentCompletion completes the --project and --task flags of cmd
+// with the cached assignments
+func addAssignmentCompletion(cmd *cobra.Command) {
+	cmd.RegisterFlagCompletionFunc("project", completeProjects)
+	cmd.RegisterFlagCompletionFunc("task", completeTasks)
+}
+
+// completeProjects offers the codes and names of the assigned projects
+func completeProjects(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	assignments, err := completionAssignments(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, assignment := range assignments {
+		description := assignment.Client.Name + "/" + assignment.Project.Name
+		if code := assignment.Project.Code; code != "" {
+			completions = appendCompletion(completions, toComplete, code, description)
+		}
+		completions = appendCompletion(completions, toComplete, assignment.Project.Name, assignment.Client.Name)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTasks offers the tasks of the project given with --project, or
+// of every assigned project when there is none
+func completeTasks(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	assignments, err := completionAssignments(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var projectId int64
+	if project, _ := cmd.Flags().GetString("project"); project != "" {
+		if matches := resolve.Projects.Find(project, assignments); len(matches) == 1 {
+			projectId = matches[0].Project.ID
+		}
+	}
+
+	var completions []string
+	for _, task := range assignedTasks(assignments, projectId) {
+		description := "Non-billable"
+		if task.Billable {
+			description = "Billable"
+		}
+		completions = appendCompletion(completions, toComplete, task.Task.Name, description)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeEntryID offers the IDs of the recent entries, described by their
+// date, project, task, hours and notes
+func completeEntryID(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if _, err := createAPIClient(); err != nil || entryCache == nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	entries, err := entryCache.Recent(cmd.Context())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, entry := range entries {
+		completions = appendCompletion(completions, toComplete, strconv.FormatInt(entry.ID, 10), describeCompletedEntry(entry))
+	}
+	// Keep the most recent entries first rather than sorting the IDs
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completionAssignments lists the assignments from the cache
+func completionAssignments(cmd *cobra.Command) ([]*api.ProjectAssignment, error) {
+	client, err := createAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	return cachedAssignments(client).ListAssignedProjects(cmd.Context(), api.ListParams{})
+}
+
+// appendCompletion adds value with its description when it starts with
+// what was typed, ignoring case
+func appendCompletion(completions []string, toComplete, value, description string) []string {
+	if !strings.HasPrefix(strings.ToLower(value), strings.ToLower(toComplete)) {
+		return completions
+	}
+	return append(completions, cobra.CompletionWithDesc(value, description))
+}
+
+// describeCompletedEntry summarizes an entry on a single line
+func describeCompletedEntry(entry *api.TimeEntry) string {
+	description := describeEntry(entry)
+	if entry.Notes != nil && *entry.Notes != "" {
+		notes, _, _ := strings.Cut(*entry.Notes, "\n")
+		description = fmt.Sprintf("%s | %s", description, notes)
+	}
+	return description
+}
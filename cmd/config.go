@@ -0,0 +1,378 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/config"
+	"harvest-cli/internal/output"
+	"harvest-cli/internal/ui"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and change the config file",
+	Long: `init, get, set, unset and validate the settings of harvest-cli.yaml.
+
+With --profile, get, set and unset apply to the settings of that profile.
+
+Settings:
+` + settingsHelp(),
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set the preferences step by step",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigInit,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:               "get [key]",
+	Short:             "Show a setting, or all the settings of the file",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSettingKey,
+	RunE:              runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:               "set <key> <value>",
+	Short:             "Change a setting",
+	Example:           "  harvest config set week_start sunday\n  harvest config set rounding 15m --profile client",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSettingKey,
+	RunE:              runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:               "unset <key>",
+	Short:             "Remove a setting, restoring its default",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSettingKey,
+	RunE:              runConfigUnset,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the location of the config file",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigPath,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Report unknown and invalid settings with their line",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigValidate,
+}
+
+// initSettings are the settings asked for by `harvest config init`, the
+// credentials being set up by `harvest auth login`
+var initSettings = []string{"output", "week_start", "rounding", "theme"}
+
+func init() {
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	addGlobalFlags(configCmd)
+}
+
+func runConfigInit(cmd *cobra.Command, args []string) error {
+	if !canPrompt() {
+		return &usageError{err: fmt.Errorf("config init asks questions and needs a terminal, use 'harvest config set' instead"), cmd: cmd}
+	}
+
+	file, err := config.ReadFile()
+	if err != nil {
+		return err
+	}
+	section, err := configSection(cmd, file)
+	if err != nil {
+		return err
+	}
+
+	for i, key := range initSettings {
+		setting, _ := config.LookupSetting(key)
+		path := append(section, key)
+
+		current := ""
+		if node := file.Lookup(path...); node != nil {
+			current = node.Value
+		}
+		value, err := ui.TextInput(ui.TextInputOptions{
+			Title:        withProfile(fmt.Sprintf("Configure harvest-cli (%d/%d)", i+1, len(initSettings))),
+			Prompt:       fmt.Sprintf("%s (%s):", setting.Description, strings.Join(setting.Values, ", ")),
+			Placeholder:  "empty for the default",
+			DefaultValue: current,
+			ValidateFunc: func(value string) error {
+				if value == "" {
+					return nil
+				}
+				return setting.Validate(value)
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to read %s: %w", key, err)
+		}
+
+		if value == "" {
+			file.Delete(path...)
+		} else if err := file.Set(&value, path...); err != nil {
+			return err
+		}
+	}
+
+	if err := file.Write(); err != nil {
+		return err
+	}
+	fmt.Printf("Settings saved to %s.\n", file.Path())
+	if !hasCredentials(selectedProfile(file)) && file.Lookup(append(section, "token_command")...) == nil {
+		fmt.Printf("Run 'harvest auth login%s' to log in.\n", profileFlag(selectedProfile(file)))
+	}
+
+	return nil
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	file, err := config.ReadFile()
+	if err != nil {
+		return err
+	}
+	section, err := configSection(cmd, file)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		var values configValues
+		for _, setting := range config.Settings {
+			if value, from, ok := lookupSetting(file, section, setting.Key); ok {
+				if setting.Secret {
+					value = "********"
+				}
+				values = append(values, configValue{Key: setting.Key, Value: value, From: from})
+			}
+		}
+		return render(cmd, values)
+	}
+
+	key := args[0]
+	if _, err := settingOf(cmd, key, section); err != nil {
+		return err
+	}
+	value, _, ok := lookupSetting(file, section, key)
+	if !ok {
+		return fmt.Errorf("%s is not set", key)
+	}
+	fmt.Println(value)
+
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, value := args[0], args[1]
+
+	file, err := config.ReadFile()
+	if err != nil {
+		return err
+	}
+	section, err := configSection(cmd, file)
+	if err != nil {
+		return err
+	}
+	setting, err := settingOf(cmd, key, section)
+	if err != nil {
+		return err
+	}
+	if err := setting.Validate(value); err != nil {
+		return &usageError{err: fmt.Errorf("%s: %w", key, err), cmd: cmd}
+	}
+	if key == "profile" {
+		value = strings.ToLower(value)
+		if value != config.DefaultProfile && !slices.Contains(file.Keys("profiles"), value) {
+			return &usageError{err: fmt.Errorf("unknown profile %q, see 'harvest profile list'", value), cmd: cmd}
+		}
+	}
+
+	if err := file.Set(&value, append(section, key)...); err != nil {
+		return err
+	}
+	if err := file.Write(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s set in %s.\n", strings.Join(append(section, key), "."), file.Path())
+
+	return nil
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	key := args[0]
+
+	file, err := config.ReadFile()
+	if err != nil {
+		return err
+	}
+	section, err := configSection(cmd, file)
+	if err != nil {
+		return err
+	}
+	if _, err := settingOf(cmd, key, section); err != nil {
+		return err
+	}
+
+	path := strings.Join(append(section, key), ".")
+	if !file.Delete(append(section, key)...) {
+		fmt.Printf("%s is not set.\n", path)
+		return nil
+	}
+	if err := file.Write(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s removed from %s.\n", path, file.Path())
+
+	return nil
+}
+
+func runConfigPath(cmd *cobra.Command, args []string) error {
+	fmt.Println(config.FilePath())
+	return nil
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	file, err := config.ReadFile()
+	if err != nil {
+		return err
+	}
+
+	problems := file.Validate()
+	if len(problems) == 0 {
+		if _, err := os.Stat(file.Path()); err != nil {
+			fmt.Printf("%s does not exist, the defaults apply.\n", file.Path())
+		} else {
+			fmt.Printf("%s is valid.\n", file.Path())
+		}
+		return nil
+	}
+
+	if err := render(cmd, configProblems(problems)); err != nil {
+		return err
+	}
+	return fmt.Errorf("%d problem(s) found in %s", len(problems), file.Path())
+}
+
+// configSection returns the path of the settings edited: the profile given
+// with --profile, or the top-level settings
+func configSection(cmd *cobra.Command, file *config.File) ([]string, error) {
+	name := strings.ToLower(profileName)
+	if name == "" || name == config.DefaultProfile {
+		return nil, nil
+	}
+	if !slices.Contains(file.Keys("profiles"), name) {
+		return nil, &usageError{err: fmt.Errorf("unknown profile %q, add it with 'harvest profile add %s'", name, name), cmd: cmd}
+	}
+	return []string{"profiles", name}, nil
+}
+
+// settingOf returns the setting of a key, refusing unknown keys and the
+// ones that cannot be set in a profile
+func settingOf(cmd *cobra.Command, key string, section []string) (config.Setting, error) {
+	setting, ok := config.LookupSetting(key)
+	if !ok {
+		return setting, &usageError{err: fmt.Errorf("unknown setting %q, see 'harvest config --help'", key), cmd: cmd}
+	}
+	if setting.Global && section != nil {
+		return setting, &usageError{err: fmt.Errorf("%s cannot be set in a profile", key), cmd: cmd}
+	}
+	return setting, nil
+}
+
+// lookupSetting returns the value of a setting in the section, falling back
+// on the top-level one a profile inherits, and where it was found
+func lookupSetting(file *config.File, section []string, key string) (string, string, bool) {
+	if section != nil {
+		if node := file.Lookup(append(section, key)...); node != nil {
+			return node.Value, section[1], true
+		}
+	}
+	if node := file.Lookup(key); node != nil {
+		return node.Value, config.DefaultProfile, true
+	}
+	return "", "", false
+}
+
+// profileFlag returns the --profile flag designating a profile in commands
+// suggested to the user
+func profileFlag(profile string) string {
+	if profile == "" {
+		return ""
+	}
+	return " --profile " + profile
+}
+
+// settingsHelp lists the settings of the schema for the help
+func settingsHelp() string {
+	var b strings.Builder
+	for _, setting := range config.Settings {
+		fmt.Fprintf(&b, "  %-20s %s\n", setting.Key, setting.Description)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// completeSettingKey completes the key argument with the settings of the
+// schema
+func completeSettingKey(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		if setting, ok := config.LookupSetting(args[0]); ok && cmd.Name() == "set" && len(args) == 1 {
+			var completions []string
+			for _, value := range setting.Values {
+				completions = appendCompletion(completions, toComplete, value, "")
+			}
+			return completions, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, setting := range config.Settings {
+		completions = appendCompletion(completions, toComplete, setting.Key, setting.Description)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// configValue is a setting of the config file
+type configValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// From is the profile the value is set in
+	From string `json:"from"`
+}
+
+type configValues []configValue
+
+func (v configValues) Table() output.Table {
+	table := output.Table{Headers: []string{"Key", "Value", "From"}}
+	for _, value := range v {
+		table.Rows = append(table.Rows, []string{value.Key, value.Value, value.From})
+	}
+	return table
+}
+
+type configProblems []config.Problem
+
+func (p configProblems) Table() output.Table {
+	table := output.Table{Headers: []string{"Line", "Key", "Problem"}}
+	for _, problem := range p {
+		table.Rows = append(table.Rows, []string{strconv.Itoa(problem.Line), problem.Key, problem.Message})
+	}
+	return table
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/config"
+	"harvest-cli/internal/output"
+)
+
+// assignmentChoice is the project and task of a new entry or timer, with
+// where each of them comes from. Zero IDs are left to the selectors.
+type assignmentChoice struct {
+	Project       string `json:"project,omitempty"`
+	ProjectId     int64  `json:"project_id,omitempty"`
+	ProjectSource string `json:"project_source"`
+	Task          string `json:"task,omitempty"`
+	TaskId        int64  `json:"task_id,omitempty"`
+	TaskSource    string `json:"task_source"`
+}
+
+// noSource is the source of a project or task chosen interactively
+const noSource = "not set, picked interactively"
+
+// chooseAssignment resolves the --project and --task flags, falling back on
+// the defaults of the .harvest.yaml of the working directory or its parents.
+// The default task only applies when the project is not given on the
+// command line, since it belongs to the default project.
+func chooseAssignment(cmd *cobra.Command, resolver *assignmentResolver, project, task string) (*assignmentChoice, error) {
+	flags := cmd.Flags()
+	choice := &assignmentChoice{ProjectSource: noSource, TaskSource: noSource}
+
+	var defaults *config.Defaults
+	if !flags.Changed("project") || !flags.Changed("task") {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		defaults, err = config.FindDefaults(wd)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read the project defaults: %w", err)
+		}
+	}
+
+	switch {
+	case flags.Changed("project"):
+		choice.Project, choice.ProjectSource = project, "--project"
+	case defaults.Project != "":
+		choice.Project, choice.ProjectSource = defaults.Project, describeDefaults(defaults)
+	}
+	switch {
+	case flags.Changed("task"):
+		choice.Task, choice.TaskSource = task, "--task"
+	case defaults.Task != "" && !flags.Changed("project"):
+		choice.Task, choice.TaskSource = defaults.Task, describeDefaults(defaults)
+	}
+
+	var err error
+	if choice.Project != "" {
+		choice.ProjectId, err = resolver.project(sourceLabel(choice.ProjectSource, "project"), choice.Project)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if choice.Task != "" {
+		choice.TaskId, err = resolver.task(sourceLabel(choice.TaskSource, "task"), choice.Task, choice.ProjectId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return choice, nil
+}
+
+// describeDefaults tells which file and branch override defaults come from
+func describeDefaults(defaults *config.Defaults) string {
+	if defaults.Branch != "" {
+		return fmt.Sprintf("%s (branch %s)", defaults.Path, defaults.Branch)
+	}
+	return defaults.Path
+}
+
+// sourceLabel names a value in resolution errors: the flag, or the setting
+// of the defaults file
+func sourceLabel(source, key string) string {
+	if source == "--"+key {
+		return source
+	}
+	return fmt.Sprintf("%s of %s", key, source)
+}
+
+func (c *assignmentChoice) Table() output.Table {
+	id := func(id int64) string {
+		if id == 0 {
+			return ""
+		}
+		return strconv.FormatInt(id, 10)
+	}
+	return output.Table{
+		Headers: []string{"Field", "Value", "ID", "Source"},
+		Rows: [][]string{
+			{"Project", c.Project, id(c.ProjectId), c.ProjectSource},
+			{"Task", c.Task, id(c.TaskId), c.TaskSource},
+		},
+	}
+}
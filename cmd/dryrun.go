@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/api"
+)
+
+// printDryRun shows a request skipped by --dry-run on stderr, leaving stdout
+// to the synthetic result. The JSON form is one object per line.
+func printDryRun(request api.DryRunRequest) {
+	if !isTableOutput() {
+		json.NewEncoder(os.Stderr).Encode(request)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Dry run, not sent: %s %s\n", request.Method, request.URL)
+	var names []string
+	for name := range request.Headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, request.Headers[name])
+	}
+	if request.Body != nil {
+		body, _ := json.MarshalIndent(request.Body, "", "  ")
+		fmt.Fprintf(os.Stderr, "%s\n", body)
+	}
+}
+
+// dryRunSuffix ends the messages of commands changing data with a reminder
+// that nothing changed under --dry-run
+func dryRunSuffix() string {
+	if !dryRun {
+		return ""
+	}
+	return " (dry run)"
+}
+
+// nameDryRunEntry fills the client, project and task names of an entry
+// synthesized by --dry-run from the user's assignments, Harvest being the
+// one naming them otherwise
+func nameDryRunEntry(cmd *cobra.Command, client *api.Client, entry *api.TimeEntry) {
+	if !dryRun || (entry.Project.Name != "" && entry.Task.Name != "") {
+		return
+	}
+	assignments, err := cachedAssignments(client).ListAssignedProjects(cmd.Context(), api.ListParams{})
+	if err != nil {
+		return
+	}
+	for _, assignment := range assignments {
+		if assignment.Project.ID != entry.Project.ID {
+			continue
+		}
+		entry.Project = assignment.Project
+		entry.Client = assignment.Client
+		for _, task := range assignment.TaskAssignments {
+			if task.Task.ID == entry.Task.ID {
+				entry.Task = task.Task
+			}
+		}
+	}
+}
@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"harvest-cli/internal/api"
+	"harvest-cli/internal/dates"
+	"harvest-cli/internal/duration"
 	"harvest-cli/internal/ui"
 	"strconv"
-	"strings"
 )
 
 var entryCmd = &cobra.Command{
@@ -19,14 +22,27 @@ var entryCmd = &cobra.Command{
 var entryCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new time entry",
-	RunE:  runEntryCreate,
+	Long: `Create a new time entry, filling in the missing options in a form.
+
+Without --project and --task, the ones set in the .harvest.yaml of the
+current directory or its parents are used, e.g. at the root of a repository:
+
+  project: acme/website
+  task: Development
+  branches:
+    release/*:
+      task: QA`,
+	RunE: runEntryCreate,
 }
 
 var (
-	entryProjectId int64
-	entryTaskId    int64
-	entryDate      string
-	entryMinutes   float64
+	entryProject  string
+	entryTask     string
+	entryDate     string
+	entryDuration string
+	entryMinutes  float64
+	entryNotes    string
+	entryExplain  bool
 )
 
 func init() {
@@ -34,10 +50,16 @@ func init() {
 
 	addGlobalFlags(entryCmd)
 
-	entryCreateCmd.Flags().Int64VarP(&entryProjectId, "project", "p", 0, "Project ID")
-	entryCreateCmd.Flags().Int64VarP(&entryTaskId, "task", "t", 0, "Task ID")
-	entryCreateCmd.Flags().StringVarP(&entryDate, "date", "d", "", "Date for the entry (YYYY-MM-DD)")
+	entryCreateCmd.Flags().StringVarP(&entryProject, "project", "p", "", "Project ID, code, name or client/name, fuzzy matched")
+	entryCreateCmd.Flags().StringVarP(&entryTask, "task", "t", "", "Task ID or name, fuzzy matched")
+	entryCreateCmd.Flags().StringVarP(&entryDate, "date", "d", "", "Date for the entry, e.g. yesterday, mon, last friday, -2d or YYYY-MM-DD")
+	entryCreateCmd.Flags().StringVar(&entryDuration, "hours", "", "Duration, e.g. 1h30m, 1:30, 1.5 or 90 (minutes above 24)")
 	entryCreateCmd.Flags().Float64VarP(&entryMinutes, "minute", "m", 0, "Duration in minutes")
+	entryCreateCmd.Flags().MarkDeprecated("minute", "use --hours instead, e.g. --hours 90m")
+	entryCreateCmd.MarkFlagsMutuallyExclusive("hours", "minute")
+	entryCreateCmd.Flags().StringVar(&entryNotes, "notes", "", "Notes describing the work (prompted for when missing)")
+	entryCreateCmd.Flags().BoolVar(&entryExplain, "explain", false, "Show where the project and task come from, without creating the entry")
+	addAssignmentCompletion(entryCreateCmd)
 }
 
 func runEntryCreate(cmd *cobra.Command, args []string) error {
@@ -46,47 +68,86 @@ func runEntryCreate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if entryProjectId == 0 {
-		selectedProject, _ := ui.SelectProjectInteractively(client)
-		entryProjectId = selectedProject.ID
+	ctx := cmd.Context()
+	flags := cmd.Flags()
+
+	// --minute is the former name of --hours, in minutes
+	if flags.Changed("minute") {
+		if err := flags.Set("hours", strconv.FormatFloat(entryMinutes, 'f', -1, 64)+"m"); err != nil {
+			return err
+		}
 	}
 
-	if entryTaskId == 0 {
-		selectedTask, _ := ui.SelectTaskInteractively(client, entryProjectId)
-		entryTaskId = selectedTask.ID
+	loadCompanySettings(cmd, client)
+
+	choice, err := chooseAssignment(cmd, newAssignmentResolver(cmd, client), entryProject, entryTask)
+	if err != nil {
+		return err
+	}
+	if entryExplain {
+		return render(cmd, choice)
+	}
+
+	values := ui.EntryFormValues{ProjectId: choice.ProjectId, TaskId: choice.TaskId, Notes: entryNotes}
+	if flags.Changed("date") {
+		values.Date, err = parseDateFlag(cmd, "date", entryDate)
+		if err != nil {
+			return err
+		}
+	}
+	if flags.Changed("hours") {
+		values.Hours, err = duration.Parse(entryDuration)
+		if err != nil {
+			return &usageError{err: err, cmd: cmd}
+		}
+	}
+	if flags.Changed("notes") {
+		if err := api.ValidateNotes(entryNotes); err != nil {
+			return &usageError{err: err, cmd: cmd}
+		}
 	}
 
-	if entryDate == "" {
-		date, _ := ui.TextInputDate("When was the entry made?")
-		entryDate = date
+	complete := values.ProjectId != 0 && values.TaskId != 0 &&
+		flags.Changed("date") && flags.Changed("hours") && flags.Changed("notes")
+
+	if !complete && !canPrompt() {
+		var missing []string
+		if values.ProjectId == 0 {
+			missing = append(missing, "--project")
+		}
+		if values.TaskId == 0 {
+			missing = append(missing, "--task")
+		}
+		if !flags.Changed("hours") {
+			missing = append(missing, "--hours")
+		}
+		if len(missing) > 0 {
+			return missingInput(cmd, strings.Join(missing, ", "))
+		}
+
+		// The date and notes are optional, like in the form
+		if values.Date == "" {
+			values.Date = time.Now().Format(dates.Layout)
+		}
+		complete = true
 	}
 
-	if entryMinutes == 0 {
-		input, err := ui.SimpleTextInput("What was the duration?", "(ex. 60m / 1h)")
+	if !complete {
+		// The form's summary doubles as the confirmation
+		submitted, err := ui.EntryForm(ctx, client, entryFormOptions(client, withProfile("New time entry"), "create the entry", values))
 		if err != nil {
-			fmt.Println("Error reading input:", err)
-		}
-
-		input = strings.TrimSpace(input)
-		if strings.HasSuffix(input, "m") {
-			minStr := strings.TrimSuffix(input, "m")
-			minutes, err := strconv.Atoi(minStr)
-			if err != nil {
-				fmt.Println("Invalid minutes format.")
-			}
-			entryMinutes = float64(minutes) / 60.0
-		} else if strings.HasSuffix(input, "h") {
-			hourStr := strings.TrimSuffix(input, "h")
-			entryMinutes, err = strconv.ParseFloat(hourStr, 64)
-			if err != nil {
-				fmt.Println("Invalid hours format.")
-			}
-		} else {
-			fmt.Println("Invalid duration format. Please use '60m' or '1h'.")
-		}
-	}
-	if !cmd.Flags().Changed("noconfirm") {
-		confirm, err := ui.Confirm("Create entry", "Are you sure you want to create this entry?")
+			return fmt.Errorf("Failed to fill in entry: %w", err)
+		}
+		if submitted == nil {
+			fmt.Println("Entry creation cancelled.")
+			return nil
+		}
+		values = *submitted
+	} else if !force {
+		if !canPrompt() {
+			return missingInput(cmd, "--noconfirm")
+		}
+		confirm, err := ui.Confirm(withProfile("Create entry"), "Are you sure you want to create this entry?")
 		if err != nil {
 			return fmt.Errorf("Failed to confirm entry creation: %w", err)
 		}
@@ -98,18 +159,97 @@ func runEntryCreate(cmd *cobra.Command, args []string) error {
 	}
 
 	entry := api.CreateEntryRequest{
-		ProjectId: entryProjectId,
-		TaskId:    entryTaskId,
-		Date:      entryDate,
-		Hours:     entryMinutes,
+		ProjectId: values.ProjectId,
+		TaskId:    values.TaskId,
+		Date:      values.Date,
+		Hours:     roundHours(values.Hours),
+		Notes:     values.Notes,
 	}
 
-	_, err = client.CreateEntry(entry)
+	created, err := client.CreateEntry(ctx, entry)
 	if err != nil {
 		return fmt.Errorf("Failed to create entry: %w", err)
 	}
 
-	fmt.Printf("Entry created successfully!")
+	nameDryRunEntry(cmd, client, created)
+	if !isTableOutput() {
+		return render(cmd, entryDetail{created})
+	}
+
+	fmt.Printf("Entry %d created successfully! (%s)%s%s\n", created.ID, duration.FormatHours(created.Hours, hoursFormat), profileSuffix(activeProfile), dryRunSuffix())
 
 	return nil
 }
+
+// resolveEntry fetches the entry whose ID is given as the first argument,
+// or lets the user pick one of their recent entries
+func resolveEntry(cmd *cobra.Command, client *api.Client, args []string) (*api.TimeEntry, error) {
+	ctx := cmd.Context()
+
+	if len(args) == 0 {
+		if !canPrompt() {
+			return nil, missingInput(cmd, "entry ID")
+		}
+		entry, err := ui.SelectEntryInteractively(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to select entry: %w", err)
+		}
+		return entry, nil
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return nil, &usageError{err: fmt.Errorf("invalid entry ID %q", args[0]), cmd: cmd}
+	}
+
+	entry, err := client.GetEntry(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get entry %d: %w", id, err)
+	}
+	return entry, nil
+}
+
+// checkEntryEditable refuses entries Harvest will not let the user change
+func checkEntryEditable(entry *api.TimeEntry) error {
+	reason := ""
+	if entry.LockedReason != nil {
+		reason = *entry.LockedReason
+	}
+
+	switch {
+	case entry.IsLocked && reason != "":
+		return fmt.Errorf("entry %d is locked: %s", entry.ID, reason)
+	case entry.IsLocked:
+		return fmt.Errorf("entry %d is locked", entry.ID)
+	case entry.IsBilled:
+		return fmt.Errorf("entry %d is already billed and can no longer be changed", entry.ID)
+	}
+	return nil
+}
+
+// notesHelp is shown as comments when notes are written in $EDITOR
+var notesHelp = []string{
+	"Describe the work done for this entry.",
+	"Lines starting with '#' are ignored, an empty message leaves the notes blank.",
+}
+
+// entryFormOptions sets up the entry form with the cached assignments and
+// the account's week start and time format
+func entryFormOptions(client *api.Client, title, submitLabel string, values ui.EntryFormValues) ui.EntryFormOptions {
+	return ui.EntryFormOptions{
+		Title:       title,
+		SubmitLabel: submitLabel,
+		Values:      values,
+		NotesHelp:   notesHelp,
+		Assignments: cachedAssignments(client),
+		Calendar: ui.CalendarOptions{
+			WeekStart:   weekStart,
+			HoursFormat: hoursFormat,
+		},
+	}
+}
+
+// describeEntry summarizes an entry for confirmation prompts
+func describeEntry(entry *api.TimeEntry) string {
+	return fmt.Sprintf("%s | %s - %s | %s", entry.SpentDate, entry.Project.Name, entry.Task.Name, duration.FormatHours(entry.Hours, hoursFormat))
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/ui"
+)
+
+var entryDeleteCmd = &cobra.Command{
+	Use:               "delete [id]",
+	Aliases:           []string{"rm"},
+	Short:             "Delete a time entry",
+	Long:              `Delete a time entry. Without an ID, pick one of your recent entries.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEntryID,
+	RunE:              runEntryDelete,
+}
+
+func init() {
+	entryCmd.AddCommand(entryDeleteCmd)
+}
+
+func runEntryDelete(cmd *cobra.Command, args []string) error {
+	client, err := createAPIClient()
+	if err != nil {
+		return err
+	}
+
+	entry, err := resolveEntry(cmd, client, args)
+	if err != nil {
+		return err
+	}
+
+	if err := checkEntryEditable(entry); err != nil {
+		return err
+	}
+
+	loadCompanySettings(cmd, client)
+
+	if !force {
+		if !canPrompt() {
+			return missingInput(cmd, "--noconfirm")
+		}
+		confirm, err := ui.Confirm(withProfile("Delete entry"), fmt.Sprintf("Are you sure you want to delete this entry?\n%s", describeEntry(entry)))
+		if err != nil {
+			return fmt.Errorf("Failed to confirm entry deletion: %w", err)
+		}
+
+		if !confirm {
+			fmt.Println("Entry deletion cancelled.")
+			return nil
+		}
+	}
+
+	if err := client.DeleteEntry(cmd.Context(), entry.ID); err != nil {
+		return fmt.Errorf("Failed to delete entry: %w", err)
+	}
+
+	// The deleted entry, as it was
+	if !isTableOutput() {
+		return render(cmd, entryDetail{entry})
+	}
+
+	fmt.Printf("Entry %d deleted.%s\n", entry.ID, dryRunSuffix())
+
+	return nil
+}
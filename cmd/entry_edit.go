@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/duration"
+	"harvest-cli/internal/ui"
+)
+
+var entryEditCmd = &cobra.Command{
+	Use:   "edit [id]",
+	Short: "Edit a time entry",
+	Long: `Edit a time entry. Without an ID, pick one of your recent entries.
+
+When no field flag is given, every field is prompted for, pre-filled with its
+current value. Otherwise only the given fields are changed.`,
+	Example: `  harvest entry edit 123 --hours 2.5
+  harvest entry edit --notes "Code review"`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEntryID,
+	RunE:              runEntryEdit,
+}
+
+var (
+	editProject string
+	editTask    string
+	editDate    string
+	editHours   string
+	editNotes   string
+)
+
+// editFieldFlags are the flags that skip the interactive prompts
+var editFieldFlags = []string{"project", "task", "date", "hours", "notes"}
+
+func init() {
+	entryCmd.AddCommand(entryEditCmd)
+
+	entryEditCmd.Flags().StringVarP(&editProject, "project", "p", "", "New project ID, code or name, fuzzy matched")
+	entryEditCmd.Flags().StringVarP(&editTask, "task", "t", "", "New task ID or name, fuzzy matched")
+	entryEditCmd.Flags().StringVarP(&editDate, "date", "d", "", "New date, e.g. yesterday, mon, -2d or YYYY-MM-DD")
+	entryEditCmd.Flags().StringVar(&editHours, "hours", "", "New duration, e.g. 1h30m, 1:30, 1.5 or 90 (minutes above 24)")
+	entryEditCmd.Flags().StringVar(&editNotes, "notes", "", "New notes")
+	addAssignmentCompletion(entryEditCmd)
+}
+
+func runEntryEdit(cmd *cobra.Command, args []string) error {
+	client, err := createAPIClient()
+	if err != nil {
+		return err
+	}
+
+	entry, err := resolveEntry(cmd, client, args)
+	if err != nil {
+		return err
+	}
+
+	if err := checkEntryEditable(entry); err != nil {
+		return err
+	}
+
+	loadCompanySettings(cmd, client)
+
+	interactive := true
+	for _, name := range editFieldFlags {
+		if cmd.Flags().Changed(name) {
+			interactive = false
+		}
+	}
+
+	if interactive && !canPrompt() {
+		last := len(editFieldFlags) - 1
+		return missingInput(cmd, fmt.Sprintf("one of --%s or --%s", strings.Join(editFieldFlags[:last], ", --"), editFieldFlags[last]))
+	}
+
+	var update api.UpdateEntryRequest
+	if interactive {
+		prompted, err := promptEntryUpdate(cmd, client, entry)
+		if err != nil {
+			return err
+		}
+		if prompted == nil {
+			fmt.Println("Entry update cancelled.")
+			return nil
+		}
+		update = *prompted
+	} else {
+		update, err = flagsEntryUpdate(cmd, client, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	if update == (api.UpdateEntryRequest{}) {
+		fmt.Println("Nothing to update.")
+		return nil
+	}
+
+	// The form's summary doubles as the confirmation
+	if !interactive && !force {
+		if !canPrompt() {
+			return missingInput(cmd, "--noconfirm")
+		}
+		confirm, err := ui.Confirm(withProfile("Edit entry"), fmt.Sprintf("Are you sure you want to update this entry?\n%s", describeEntry(entry)))
+		if err != nil {
+			return fmt.Errorf("Failed to confirm entry update: %w", err)
+		}
+
+		if !confirm {
+			fmt.Println("Entry update cancelled.")
+			return nil
+		}
+	}
+
+	updated, err := client.UpdateEntry(cmd.Context(), entry.ID, update)
+	if err != nil {
+		return fmt.Errorf("Failed to update entry: %w", err)
+	}
+
+	nameDryRunEntry(cmd, client, updated)
+	if !isTableOutput() {
+		return render(cmd, entryDetail{updated})
+	}
+
+	fmt.Printf("Entry %d updated.%s\n", updated.ID, dryRunSuffix())
+
+	return nil
+}
+
+// flagsEntryUpdate builds the update from the field flags that were set
+func flagsEntryUpdate(cmd *cobra.Command, client *api.Client, entry *api.TimeEntry) (api.UpdateEntryRequest, error) {
+	var update api.UpdateEntryRequest
+	flags := cmd.Flags()
+	resolver := newAssignmentResolver(cmd, client)
+
+	projectId := entry.Project.ID
+	if flags.Changed("project") {
+		id, err := resolver.project("--project", editProject)
+		if err != nil {
+			return update, err
+		}
+		projectId = id
+		update.ProjectId = &projectId
+	}
+	if flags.Changed("task") {
+		id, err := resolver.task("--task", editTask, projectId)
+		if err != nil {
+			return update, err
+		}
+		update.TaskId = &id
+	}
+	if flags.Changed("date") {
+		date, err := parseDateFlag(cmd, "date", editDate)
+		if err != nil {
+			return update, err
+		}
+		update.SpentDate = &date
+	}
+	if flags.Changed("hours") {
+		hours, err := duration.Parse(editHours)
+		if err != nil {
+			return update, &usageError{err: err, cmd: cmd}
+		}
+		hours = roundHours(hours)
+		update.Hours = &hours
+	}
+	if flags.Changed("notes") {
+		if err := api.ValidateNotes(editNotes); err != nil {
+			return update, &usageError{err: err, cmd: cmd}
+		}
+		update.Notes = &editNotes
+	}
+
+	// Harvest needs the task again when the project changes
+	if update.ProjectId != nil && update.TaskId == nil {
+		return update, &usageError{err: fmt.Errorf("--task is required when changing the project"), cmd: cmd}
+	}
+
+	return update, nil
+}
+
+// promptEntryUpdate opens the entry form pre-filled with the entry's
+// current values and keeps the ones that changed. It returns nil when the
+// user cancelled.
+func promptEntryUpdate(cmd *cobra.Command, client *api.Client, entry *api.TimeEntry) (*api.UpdateEntryRequest, error) {
+	currentNotes := ""
+	if entry.Notes != nil {
+		currentNotes = *entry.Notes
+	}
+	current := ui.EntryFormValues{
+		ProjectId: entry.Project.ID,
+		TaskId:    entry.Task.ID,
+		Date:      entry.SpentDate,
+		Hours:     entry.Hours,
+		Notes:     currentNotes,
+	}
+
+	title := withProfile(fmt.Sprintf("Edit entry %d", entry.ID))
+	values, err := ui.EntryForm(cmd.Context(), client, entryFormOptions(client, title, "update the entry", current))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fill in entry: %w", err)
+	}
+	if values == nil {
+		return nil, nil
+	}
+
+	var update api.UpdateEntryRequest
+	if values.ProjectId != current.ProjectId || values.TaskId != current.TaskId {
+		update.ProjectId = &values.ProjectId
+		update.TaskId = &values.TaskId
+	}
+	if values.Date != current.Date {
+		update.SpentDate = &values.Date
+	}
+	if duration.Round(values.Hours, hoursFormat) != duration.Round(current.Hours, hoursFormat) {
+		hours := roundHours(values.Hours)
+		update.Hours = &hours
+	}
+	if values.Notes != current.Notes {
+		update.Notes = &values.Notes
+	}
+
+	return &update, nil
+}
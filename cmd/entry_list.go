@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/dates"
+	"harvest-cli/internal/duration"
+	"harvest-cli/internal/output"
+)
+
+var entryListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List time entries",
+	Long: `List your time entries, newest first.
+
+By default the entries of the last 7 days are shown. Use --date with a range
+such as this-week, last-month or 2024-05-01..2024-05-07, or --from and --to,
+to pick another range, and -o to get json, yaml, csv, tsv or a go-template.`,
+	Example: `  harvest entry list --date last-week
+  harvest entry list --from 2024-05-01 --to 2024-05-31
+  harvest entry list --from mon
+  harvest entry list --project 123 --unbilled -o csv
+  harvest entry list -o 'go-template={{range .}}{{.id}} {{.hours}}{{"\n"}}{{end}}'`,
+	Args: cobra.NoArgs,
+	RunE: runEntryList,
+}
+
+var (
+	listDate     string
+	listFrom     string
+	listTo       string
+	listProject  string
+	listTask     string
+	listUnbilled bool
+)
+
+func init() {
+	entryCmd.AddCommand(entryListCmd)
+
+	addListFlags(entryListCmd)
+
+	entryListCmd.Flags().StringVarP(&listDate, "date", "d", "", "Only entries spent in this range, e.g. today, this-week, last-month or 2024-05-01..2024-05-07")
+	entryListCmd.Flags().StringVar(&listFrom, "from", "", "Only entries spent on or after this date, e.g. mon or -2w (default: 7 days ago)")
+	entryListCmd.Flags().StringVar(&listTo, "to", "", "Only entries spent on or before this date")
+	entryListCmd.Flags().StringVarP(&listProject, "project", "p", "", "Only entries of this project ID, code or name, fuzzy matched")
+	entryListCmd.Flags().StringVarP(&listTask, "task", "t", "", "Only entries of this task ID or name, fuzzy matched")
+	entryListCmd.Flags().BoolVar(&listUnbilled, "unbilled", false, "Only entries that are not billed yet")
+	addAssignmentCompletion(entryListCmd)
+}
+
+func runEntryList(cmd *cobra.Command, args []string) error {
+	if _, err := output.NewRenderer(outputFormat); err != nil {
+		return &usageError{err: err, cmd: cmd}
+	}
+
+	client, err := createAPIClient()
+	if err != nil {
+		return err
+	}
+
+	loadCompanySettings(cmd, client)
+
+	// Managers and administrators would see everyone's entries otherwise
+	me, err := client.GetMe(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("Failed to get current user: %w", err)
+	}
+
+	query := api.TimeEntryQuery{UserId: me.ID}
+	if err := listRange(cmd, &query); err != nil {
+		return err
+	}
+
+	resolver := newAssignmentResolver(cmd, client)
+	if cmd.Flags().Changed("project") {
+		if query.ProjectId, err = resolver.project("--project", listProject); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("task") {
+		if query.TaskId, err = resolver.task("--task", listTask, query.ProjectId); err != nil {
+			return err
+		}
+	}
+	if listUnbilled {
+		billed := false
+		query.IsBilled = &billed
+	}
+
+	entries := entryList{}
+	skipped := 0
+	for entry, err := range client.Entries(cmd.Context(), query) {
+		if err != nil {
+			return fmt.Errorf("Failed to list entries: %w", err)
+		}
+		if filter != "" && !entryContains(entry, filter) {
+			continue
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		entries = append(entries, entry)
+		// Stop paging through Harvest once we have enough
+		if limit > 0 && len(entries) >= limit {
+			break
+		}
+	}
+
+	if len(entries) == 0 && isTableOutput() {
+		fmt.Fprintln(cmd.OutOrStdout(), "No entries found.")
+		return nil
+	}
+
+	return render(cmd, entries)
+}
+
+// listRange sets the dates of the query from --date, or --from and --to,
+// defaulting to the last 7 days
+func listRange(cmd *cobra.Command, query *api.TimeEntryQuery) error {
+	flags := cmd.Flags()
+	if flags.Changed("date") {
+		if flags.Changed("from") || flags.Changed("to") {
+			return &usageError{err: fmt.Errorf("--date cannot be combined with --from or --to"), cmd: cmd}
+		}
+		r, err := parseRangeFlag(cmd, "date", listDate)
+		if err != nil {
+			return err
+		}
+		query.From, query.To = r.FromISO(), r.ToISO()
+		return nil
+	}
+
+	var err error
+	if flags.Changed("from") {
+		if query.From, err = parseDateFlag(cmd, "from", listFrom); err != nil {
+			return err
+		}
+	} else {
+		query.From = time.Now().AddDate(0, 0, -6).Format(dates.Layout)
+	}
+	if flags.Changed("to") {
+		if query.To, err = parseDateFlag(cmd, "to", listTo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entryContains reports whether the entry's notes, client, project or task
+// contain text, ignoring case
+func entryContains(entry *api.TimeEntry, text string) bool {
+	fields := []string{entry.Client.Name, entry.Project.Name, entry.Task.Name}
+	if entry.Notes != nil {
+		fields = append(fields, *entry.Notes)
+	}
+
+	text = strings.ToLower(text)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// entryList renders time entries as a table
+type entryList []*api.TimeEntry
+
+func (l entryList) Table() output.Table {
+	table := output.Table{
+		Headers: []string{"ID", "Date", "Client", "Project", "Task", "Hours", "Notes"},
+	}
+
+	for _, entry := range l {
+		hours := duration.FormatHours(entry.Hours, hoursFormat)
+		if entry.IsRunning {
+			hours += " (running)"
+		}
+		notes := ""
+		if entry.Notes != nil {
+			notes = *entry.Notes
+		}
+
+		table.Rows = append(table.Rows, []string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.SpentDate,
+			entry.Client.Name,
+			entry.Project.Name,
+			entry.Task.Name,
+			hours,
+			notes,
+		})
+	}
+
+	return table
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/duration"
+	"harvest-cli/internal/output"
+)
+
+var entryShowCmd = &cobra.Command{
+	Use:               "show [id]",
+	Short:             "Show a time entry",
+	Long:              `Show the details of a time entry. Without an ID, pick one of your recent entries.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEntryID,
+	RunE:              runEntryShow,
+}
+
+func init() {
+	entryCmd.AddCommand(entryShowCmd)
+}
+
+func runEntryShow(cmd *cobra.Command, args []string) error {
+	if _, err := output.NewRenderer(outputFormat); err != nil {
+		return &usageError{err: err, cmd: cmd}
+	}
+
+	client, err := createAPIClient()
+	if err != nil {
+		return err
+	}
+
+	entry, err := resolveEntry(cmd, client, args)
+	if err != nil {
+		return err
+	}
+
+	if isTableOutput() {
+		loadCompanySettings(cmd, client)
+	}
+
+	return render(cmd, entryDetail{entry})
+}
+
+// entryDetail renders a single time entry as a field/value table
+type entryDetail struct {
+	*api.TimeEntry
+}
+
+func (d entryDetail) Table() output.Table {
+	entry := d.TimeEntry
+
+	optional := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+
+	rows := [][]string{
+		{"ID", strconv.FormatInt(entry.ID, 10)},
+		{"Date", entry.SpentDate},
+		{"Client", entry.Client.Name},
+		{"Project", entry.Project.Name},
+		{"Task", entry.Task.Name},
+		{"Hours", duration.FormatHours(entry.Hours, hoursFormat)},
+		{"Notes", optional(entry.Notes)},
+		{"Started", optional(entry.StartedTime)},
+		{"Ended", optional(entry.EndedTime)},
+		{"Running", strconv.FormatBool(entry.IsRunning)},
+		{"Billable", strconv.FormatBool(entry.Billable)},
+		{"Billed", strconv.FormatBool(entry.IsBilled)},
+		{"Approval", entry.ApprovalStatus},
+		{"Locked", strconv.FormatBool(entry.IsLocked)},
+	}
+	if entry.LockedReason != nil {
+		rows = append(rows, []string{"Locked reason", *entry.LockedReason})
+	}
+	if entry.ExternalReference != nil {
+		rows = append(rows, []string{"Reference", entry.ExternalReference.Permalink})
+	}
+
+	return output.Table{
+		Headers: []string{"Field", "Value"},
+		Rows:    rows,
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/api/fake"
+	"harvest-cli/internal/dates"
+)
+
+// newFakeHarvest starts a fake Harvest API the commands log in to, with
+// the config, credentials and cache of a temporary home
+func newFakeHarvest(t *testing.T) *fake.Server {
+	t.Helper()
+	srv := fake.NewServer()
+	t.Cleanup(srv.Close)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", home+"/.cache")
+	t.Setenv("HARVEST_PROFILE", "")
+	t.Setenv("HARVEST_BASE_URL", srv.BaseURL())
+	t.Setenv("HARVEST_TOKEN", srv.Token)
+	t.Setenv("HARVEST_ACCOUNT_ID", srv.AccountID)
+	return srv
+}
+
+// execute runs the command line through rootCmd, starting from the default
+// value of every flag as a new process would
+func execute(t *testing.T, args ...string) error {
+	t.Helper()
+	resetFlags(rootCmd)
+	rootCmd.SetArgs(args)
+	return rootCmd.ExecuteContext(context.Background())
+}
+
+// resetFlags sets the flags of cmd and its subcommands back to their
+// defaults, since their variables outlive a run
+func resetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		flag.Value.Set(flag.DefValue)
+		flag.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+func TestEntryCreate(t *testing.T) {
+	today := time.Now().Format(dates.Layout)
+
+	tests := []struct {
+		name string
+		// args follow "entry create"; PROJECT and TASK are replaced with
+		// the IDs of the assignment
+		args []string
+		// want is the entry stored, nil when the command fails
+		want *api.TimeEntry
+	}{
+		{
+			name: "by name",
+			args: []string{"--project", "Website", "--task", "Design", "--hours", "1h", "--date", "2024-05-01", "--notes", "Mockups"},
+			want: &api.TimeEntry{SpentDate: "2024-05-01", Hours: 1, Notes: ptr("Mockups")},
+		},
+		{
+			name: "by ID, today without notes",
+			args: []string{"--project", "PROJECT", "--task", "TASK", "--hours", "1h"},
+			want: &api.TimeEntry{SpentDate: today, Hours: 1},
+		},
+		{
+			name: "minutes",
+			args: []string{"--project", "Website", "--task", "Design", "--hours", "90", "--date", "2024-05-01"},
+			want: &api.TimeEntry{SpentDate: "2024-05-01", Hours: 1.5},
+		},
+		{
+			name: "missing hours",
+			args: []string{"--project", "Website", "--task", "Design"},
+		},
+		{
+			name: "unknown project",
+			args: []string{"--project", "Intranet", "--task", "Design", "--hours", "1h"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newFakeHarvest(t)
+			assignment := srv.AddProject(api.ClientData{Name: "ACME"}, api.Project{Name: "Website"}, api.Task{Name: "Design"}, api.Task{Name: "QA"})
+			design := assignment.TaskAssignments[0].Task
+
+			args := []string{"entry", "create", "--noconfirm", "--no-input"}
+			for _, arg := range tt.args {
+				switch arg {
+				case "PROJECT":
+					arg = strconv.FormatInt(assignment.Project.ID, 10)
+				case "TASK":
+					arg = strconv.FormatInt(design.ID, 10)
+				}
+				args = append(args, arg)
+			}
+			err := execute(t, args...)
+
+			entries := srv.Entries()
+			if tt.want == nil {
+				if err == nil {
+					t.Error("entry create succeeded, want a failure")
+				}
+				if len(entries) != 0 {
+					t.Errorf("entry create stored %d entries, want none", len(entries))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("entry create: %s", FormatError(err))
+			}
+			if len(entries) != 1 {
+				t.Fatalf("entry create stored %d entries, want 1", len(entries))
+			}
+
+			entry := entries[0]
+			if entry.Project.ID != assignment.Project.ID || entry.Task.ID != design.ID {
+				t.Errorf("got an entry of %s/%s, want Website/Design", entry.Project.Name, entry.Task.Name)
+			}
+			if entry.SpentDate != tt.want.SpentDate || entry.Hours != tt.want.Hours {
+				t.Errorf("got %v hours on %s, want %v hours on %s", entry.Hours, entry.SpentDate, tt.want.Hours, tt.want.SpentDate)
+			}
+			if notes := deref(entry.Notes); notes != deref(tt.want.Notes) {
+				t.Errorf("got notes %q, want %q", notes, deref(tt.want.Notes))
+			}
+			if entry.IsRunning {
+				t.Error("got a running timer, want an entry with hours")
+			}
+		})
+	}
+}
+
+func ptr(s string) *string { return &s }
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/api"
+)
+
+// Exit codes returned by the harvest binary
+const (
+	ExitOK          = 0
+	ExitError       = 1
+	ExitUsage       = 2
+	ExitAuth        = 3
+	ExitForbidden   = 4
+	ExitNotFound    = 5
+	ExitValidation  = 6
+	ExitRateLimited = 7
+	ExitServer      = 8
+)
+
+// usageError marks errors caused by invalid flags or arguments
+type usageError struct {
+	err error
+	cmd *cobra.Command
+}
+
+func (e *usageError) Error() string {
+	return fmt.Sprintf("%v\nRun '%s --help' for usage.", e.err, e.cmd.CommandPath())
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// ExitCode maps an error returned by Execute to the process exit code
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		return ExitUsage
+	}
+
+	apiErr, ok := api.AsError(err)
+	if !ok {
+		return ExitError
+	}
+
+	switch {
+	case apiErr.IsUnauthorized():
+		return ExitAuth
+	case apiErr.IsForbidden():
+		return ExitForbidden
+	case apiErr.IsNotFound():
+		return ExitNotFound
+	case apiErr.IsValidation():
+		return ExitValidation
+	case apiErr.IsRateLimited():
+		return ExitRateLimited
+	case apiErr.StatusCode >= 500:
+		return ExitServer
+	}
+	return ExitError
+}
+
+// FormatError turns an error returned by Execute into a message for the
+// user, replacing raw API failures with an explanation of what to do next
+func FormatError(err error) string {
+	apiErr, ok := api.AsError(err)
+	if !ok {
+		return err.Error()
+	}
+
+	// Keep the context added by the command, e.g. "Failed to create entry: "
+	prefix := strings.TrimSuffix(err.Error(), apiErr.Error())
+
+	var message string
+	switch {
+	case apiErr.IsUnauthorized():
+		message = "Your Harvest token is invalid or has expired, run 'harvest auth login' to sign in again"
+	case apiErr.IsForbidden():
+		message = fmt.Sprintf("You don't have access to this in Harvest: %s", apiErr.Reason())
+	case apiErr.IsNotFound():
+		message = fmt.Sprintf("Not found in Harvest: %s", apiErr.Reason())
+	case apiErr.IsValidation():
+		message = fmt.Sprintf("Harvest rejected the request: %s", apiErr.Reason())
+	case apiErr.IsRateLimited():
+		message = "Harvest rate limit reached, try again in a moment"
+		if apiErr.RetryAfter > 0 {
+			message = fmt.Sprintf("Harvest rate limit reached, try again in %s", apiErr.RetryAfter)
+		}
+	case apiErr.StatusCode >= 500:
+		message = fmt.Sprintf("Harvest is having trouble (status %d), try again later", apiErr.StatusCode)
+	default:
+		message = apiErr.Error()
+	}
+
+	if verbose {
+		message += fmt.Sprintf(" (%s)", apiErr.Error())
+	}
+
+	return prefix + message
+}
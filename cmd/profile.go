@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/config"
+	"harvest-cli/internal/output"
+	"harvest-cli/internal/ui"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage the profiles of the config file",
+	Long: `list, switch, add and remove named profiles, e.g. one per Harvest account.
+
+Profiles live under "profiles" in harvest-cli.yaml and override the
+top-level settings, called the "default" profile:
+
+  account_id: "111111"
+  profile: agency
+  profiles:
+    agency:
+      account_id: "222222"
+    client:
+      token_command: pass show harvest/client
+
+The profile is chosen with --profile, then HARVEST_PROFILE, then the one set
+with 'harvest profile use'. Each profile logs in separately with
+'harvest auth login --profile <name>'.`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the profiles, marking the active one",
+	Args:    cobra.NoArgs,
+	RunE:    runProfileList,
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:               "use <name>",
+	Short:             "Make a profile the one used by default",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE:              runProfileUse,
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a profile",
+	Example: `  harvest profile add client --account-id 222222
+  harvest auth login --profile client`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProfileAdd,
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:               "remove <name>",
+	Aliases:           []string{"rm"},
+	Short:             "Remove a profile and its stored credentials",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	RunE:              runProfileRemove,
+}
+
+var (
+	profileAccountId    string
+	profileTokenCommand string
+	profileBaseURL      string
+)
+
+func init() {
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileAddCmd)
+	profileCmd.AddCommand(profileRemoveCmd)
+
+	addGlobalFlags(profileCmd)
+
+	profileAddCmd.Flags().StringVar(&profileAccountId, "account-id", "", "Harvest account ID of the profile")
+	profileAddCmd.Flags().StringVar(&profileTokenCommand, "token-command", "", "Command printing the token of the profile")
+	profileAddCmd.Flags().StringVar(&profileBaseURL, "base-url", "", "Harvest API root of the profile")
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	file, err := config.ReadFile()
+	if err != nil {
+		return err
+	}
+
+	active := selectedProfile(file)
+	list := profileList{{
+		Name:      config.DefaultProfile,
+		Active:    active == "",
+		AccountId: scalar(file, "account_id"),
+		LoggedIn:  hasCredentials(""),
+	}}
+	for _, name := range file.Keys("profiles") {
+		list = append(list, profileInfo{
+			Name:      name,
+			Active:    name == active,
+			AccountId: cmp.Or(scalar(file, "profiles", name, "account_id"), scalar(file, "account_id")),
+			LoggedIn:  hasCredentials(name),
+		})
+	}
+
+	return render(cmd, list)
+}
+
+func runProfileUse(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+
+	file, err := config.ReadFile()
+	if err != nil {
+		return err
+	}
+
+	if name == config.DefaultProfile {
+		file.Delete("profile")
+	} else {
+		if !slices.Contains(file.Keys("profiles"), name) {
+			return &usageError{err: fmt.Errorf("unknown profile %q, see 'harvest profile list'", name), cmd: cmd}
+		}
+		file.Set(&name, "profile")
+	}
+	if err := file.Write(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Switched to profile %s.\n", name)
+	if env := os.Getenv("HARVEST_PROFILE"); env != "" && env != name {
+		fmt.Printf("HARVEST_PROFILE=%s still takes precedence in this shell.\n", env)
+	}
+
+	return nil
+}
+
+func runProfileAdd(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+	if err := config.ValidateProfileName(name); err != nil {
+		return &usageError{err: err, cmd: cmd}
+	}
+
+	file, err := config.ReadFile()
+	if err != nil {
+		return err
+	}
+	if slices.Contains(file.Keys("profiles"), name) {
+		return &usageError{err: fmt.Errorf("profile %q already exists", name), cmd: cmd}
+	}
+
+	if err := file.Set(nil, "profiles", name); err != nil {
+		return err
+	}
+	settings := map[string]string{
+		"account_id":    profileAccountId,
+		"token_command": profileTokenCommand,
+		"base_url":      profileBaseURL,
+	}
+	for _, key := range []string{"account_id", "token_command", "base_url"} {
+		if value := settings[key]; value != "" {
+			file.Set(&value, "profiles", name, key)
+		}
+	}
+	if err := file.Write(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Profile %s added to %s.\n", name, file.Path())
+	if profileTokenCommand == "" {
+		fmt.Printf("Run 'harvest auth login --profile %s' to log in.\n", name)
+	}
+
+	return nil
+}
+
+func runProfileRemove(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+	if name == config.DefaultProfile {
+		return &usageError{err: fmt.Errorf("the default profile holds the top-level settings and cannot be removed"), cmd: cmd}
+	}
+
+	file, err := config.ReadFile()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(file.Keys("profiles"), name) {
+		return &usageError{err: fmt.Errorf("unknown profile %q, see 'harvest profile list'", name), cmd: cmd}
+	}
+
+	if !force {
+		if !canPrompt() {
+			return missingInput(cmd, "--noconfirm")
+		}
+		confirm, err := ui.Confirm("Remove profile", fmt.Sprintf("Are you sure you want to remove the profile %s and its stored credentials?", name))
+		if err != nil {
+			return fmt.Errorf("Failed to confirm profile removal: %w", err)
+		}
+		if !confirm {
+			fmt.Println("Profile removal cancelled.")
+			return nil
+		}
+	}
+
+	file.Delete("profiles", name)
+	if len(file.Keys("profiles")) == 0 {
+		file.Delete("profiles")
+	}
+	if selected := file.Lookup("profile"); selected != nil && selected.Value == name {
+		file.Delete("profile")
+	}
+	if err := file.Write(); err != nil {
+		return err
+	}
+	if _, err := config.RemoveCredentials(name); err != nil {
+		return err
+	}
+
+	fmt.Printf("Profile %s removed.\n", name)
+
+	return nil
+}
+
+// selectedProfile returns the profile used when --profile is not given,
+// empty for the default one
+func selectedProfile(file *config.File) string {
+	name := cmp.Or(profileName, os.Getenv("HARVEST_PROFILE"), scalar(file, "profile"))
+	name = strings.ToLower(name)
+	if name == config.DefaultProfile {
+		return ""
+	}
+	return name
+}
+
+// scalar returns the value at the path of keys in the config file
+func scalar(file *config.File, keys ...string) string {
+	if node := file.Lookup(keys...); node != nil {
+		return node.Value
+	}
+	return ""
+}
+
+// hasCredentials reports whether `harvest auth login` stored credentials
+// for the profile
+func hasCredentials(profile string) bool {
+	path, err := config.CredentialsPath(profile)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// completeProfiles offers the profile names of the config file
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	file, err := config.ReadFile()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	completions := appendCompletion(nil, toComplete, config.DefaultProfile, "Top-level settings")
+	for _, name := range file.Keys("profiles") {
+		completions = appendCompletion(completions, toComplete, name, cmp.Or(scalar(file, "profiles", name, "account_id"), "Profile"))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfileArg completes the profile name argument
+func completeProfileArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeProfiles(cmd, args, toComplete)
+}
+
+// profileInfo describes a profile of the config file
+type profileInfo struct {
+	Name      string `json:"name"`
+	Active    bool   `json:"active"`
+	AccountId string `json:"account_id,omitempty"`
+	LoggedIn  bool   `json:"logged_in"`
+}
+
+type profileList []profileInfo
+
+func (l profileList) Table() output.Table {
+	table := output.Table{Headers: []string{"", "Profile", "Account ID", "Logged in"}}
+	for _, profile := range l {
+		marker := ""
+		if profile.Active {
+			marker = "*"
+		}
+		loggedIn := "no"
+		if profile.LoggedIn {
+			loggedIn = "yes"
+		}
+		table.Rows = append(table.Rows, []string{marker, profile.Name, profile.AccountId, loggedIn})
+	}
+	return table
+}
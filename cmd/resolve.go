@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/resolve"
+	"harvest-cli/internal/ui"
+)
+
+// assignmentResolver turns the --project and --task values, given as an ID,
+// code, name or fuzzy string, into IDs. The user's project assignments are
+// read from the cache on first use.
+type assignmentResolver struct {
+	cmd         *cobra.Command
+	lister      ui.AssignmentLister
+	assignments []*api.ProjectAssignment
+}
+
+func newAssignmentResolver(cmd *cobra.Command, client *api.Client) *assignmentResolver {
+	return &assignmentResolver{cmd: cmd, lister: cachedAssignments(client)}
+}
+
+func (r *assignmentResolver) load() ([]*api.ProjectAssignment, error) {
+	if r.assignments == nil {
+		assignments, err := r.lister.ListAssignedProjects(r.cmd.Context(), api.ListParams{})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list projects: %w", err)
+		}
+		r.assignments = assignments
+	}
+	return r.assignments, nil
+}
+
+// project returns the ID of the project designated by query, which comes
+// from source, e.g. "--project"
+func (r *assignmentResolver) project(source, query string) (int64, error) {
+	assignments, err := r.load()
+	if err != nil {
+		return 0, err
+	}
+
+	matches := resolve.Projects.Find(query, assignments)
+	switch {
+	case len(matches) == 1:
+		return matches[0].Project.ID, nil
+	case len(matches) == 0:
+		// Entries may be filtered on projects the user is no longer assigned to
+		if id, err := strconv.ParseInt(query, 10, 64); err == nil {
+			return id, nil
+		}
+		return 0, &usageError{err: fmt.Errorf("%s %q matches none of your projects%s", source, query, refreshHint), cmd: r.cmd}
+	case !canPrompt():
+		return 0, &usageError{err: ambiguousError(source, query, matches, resolve.Projects), cmd: r.cmd}
+	}
+
+	selected, err := ui.SelectProjectMatching(r.cmd.Context(), assignments, query)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to select project: %w", err)
+	}
+	return selected.Project.ID, nil
+}
+
+// task returns the ID of the task designated by query, which comes from
+// source, among the tasks of the project, or of every assigned project when
+// projectId is zero
+func (r *assignmentResolver) task(source, query string, projectId int64) (int64, error) {
+	assignments, err := r.load()
+	if err != nil {
+		return 0, err
+	}
+
+	tasks := assignedTasks(assignments, projectId)
+	matches := resolve.Tasks.Find(query, tasks)
+	switch {
+	case len(matches) == 1:
+		return matches[0].Task.ID, nil
+	case len(matches) == 0:
+		if id, err := strconv.ParseInt(query, 10, 64); err == nil {
+			return id, nil
+		}
+		return 0, &usageError{err: fmt.Errorf("%s %q matches none of the tasks you can log time to%s", source, query, refreshHint), cmd: r.cmd}
+	case !canPrompt():
+		return 0, &usageError{err: ambiguousError(source, query, matches, resolve.Tasks), cmd: r.cmd}
+	}
+
+	selected, err := ui.SelectTaskMatching(r.cmd.Context(), tasks, query)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to select task: %w", err)
+	}
+	return selected.Task.ID, nil
+}
+
+// refreshHint ends the errors of queries matching no assignment, which may
+// be missing from the cache
+const refreshHint = ` (run "harvest cache refresh" if it was assigned recently)`
+
+// assignedTasks returns the tasks of the project, or the tasks of every
+// project once each when projectId is zero or not one of the assignments
+func assignedTasks(assignments []*api.ProjectAssignment, projectId int64) []*api.TaskAssignment {
+	if !slices.ContainsFunc(assignments, func(a *api.ProjectAssignment) bool { return a.Project.ID == projectId }) {
+		projectId = 0
+	}
+
+	var tasks []*api.TaskAssignment
+	seen := map[int64]bool{}
+	for _, assignment := range assignments {
+		if projectId != 0 && assignment.Project.ID != projectId {
+			continue
+		}
+		for _, task := range assignment.TaskAssignments {
+			if !seen[task.Task.ID] {
+				seen[task.Task.ID] = true
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks
+}
+
+// ambiguousError lists the candidates of a query matching several records
+func ambiguousError[T any](source, query string, matches []T, matcher resolve.Matcher[T]) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %q matches %d candidates, use one of the IDs:", source, query, len(matches))
+	for _, match := range matches {
+		fmt.Fprintf(&b, "\n  %-10d %s", matcher.ID(match), matcher.Label(match))
+	}
+	return fmt.Errorf("%s", b.String())
+}
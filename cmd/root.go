@@ -1,19 +1,40 @@
 package cmd
 
 import (
-    "github.com/spf13/cobra"
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
 )
 
 var rootCmd = &cobra.Command{
-    Use:   "harvest",
-    Short: "API wrapper for managing entries and tasks",
-    Long:  `A command-line interface for interacting with the API`,
+	Use:   "harvest",
+	Short: "API wrapper for managing entries and tasks",
+	Long:  `A command-line interface for interacting with the API`,
+	// Errors are reported by main through FormatError
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 func Execute() error {
-    return rootCmd.Execute()
+	// Ctrl-C cancels the command context and with it any in-flight request
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
-    rootCmd.AddCommand(entryCmd)
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err: err, cmd: cmd}
+	})
+
+	rootCmd.AddCommand(entryCmd)
+	rootCmd.AddCommand(timerCmd)
+	rootCmd.AddCommand(cacheCmd)
+	rootCmd.AddCommand(authCmd)
+	rootCmd.AddCommand(profileCmd)
+	rootCmd.AddCommand(configCmd)
 }
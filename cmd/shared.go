@@ -2,13 +2,24 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 	"harvest-cli/internal/api"
+	"harvest-cli/internal/cache"
 	"harvest-cli/internal/config"
+	"harvest-cli/internal/dates"
+	"harvest-cli/internal/duration"
+	"harvest-cli/internal/output"
+	"harvest-cli/internal/styles"
+	"harvest-cli/internal/ui"
 )
 
 var (
+	profileName  string
 	token        string
 	accountId    string
 	timeout      int
@@ -18,19 +29,236 @@ var (
 	offset       int
 	filter       string
 	force        bool
+	noInput      bool
+	dryRun       bool
 )
 
 func addGlobalFlags(cmd *cobra.Command) {
-	cmd.PersistentFlags().IntVar(&timeout, "timeout", 30, "Request timeout")
+	cmd.PersistentFlags().StringVar(&profileName, "profile", "", "Config profile to use (default: $HARVEST_PROFILE or the one set with 'harvest profile use')")
+	cmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	cmd.PersistentFlags().IntVar(&timeout, "timeout", 30, "Request timeout in seconds")
 	cmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
 	cmd.PersistentFlags().BoolVar(&force, "noconfirm", false, "Skip confirmation")
+	cmd.PersistentFlags().BoolVar(&noInput, "no-input", false, "Never prompt, fail when a value is missing (implied when stdin or stdout is not a terminal)")
+	cmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print the requests that would change data, with the token redacted, instead of sending them")
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "",
+		fmt.Sprintf("Output format (%s) (default: the output setting or %s)", strings.Join(output.Formats, "|"), output.FormatTable))
 }
 
-func createAPIClient() (*api.Client, error) {
-	cfg, err := config.Load()
+// Account preferences, set by loadCompanySettings
+var (
+	// hoursFormat is how durations are rounded and displayed
+	hoursFormat = duration.Decimal
+	// weekStart is the first day of this-week and last-week ranges
+	weekStart = time.Monday
+	// weekStartSet tells weekStart comes from the config file, which wins
+	// over the account setting
+	weekStartSet bool
+	// roundingIncrement is the multiple of an hour durations are rounded up
+	// to, zero when they are not
+	roundingIncrement float64
+)
+
+// loadCompanySettings reads the account's time format and week start,
+// keeping the defaults when they cannot be fetched
+func loadCompanySettings(cmd *cobra.Command, client *api.Client) {
+	company, err := client.GetCompany(cmd.Context())
+	if err != nil {
+		if verbose {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Could not read the account settings: %v\n", err)
+		}
+		return
+	}
+
+	if format, err := duration.ParseFormat(company.TimeFormat); err == nil {
+		hoursFormat = format
+	}
+	if day, err := dates.ParseWeekday(company.WeekStartDay); err == nil && !weekStartSet {
+		weekStart = day
+	}
+}
+
+// parseDateFlag resolves the date expression given to a flag, e.g.
+// "yesterday" or "-2d", into the Harvest date format
+func parseDateFlag(cmd *cobra.Command, name, value string) (string, error) {
+	date, err := dates.Parse(value, time.Now())
+	if err != nil {
+		return "", &usageError{err: fmt.Errorf("--%s: %w", name, err), cmd: cmd}
+	}
+	return date.Format(dates.Layout), nil
+}
+
+// parseRangeFlag resolves the range expression given to a flag, e.g.
+// "this-week" or "2024-05-01..2024-05-07"
+func parseRangeFlag(cmd *cobra.Command, name, value string) (dates.Range, error) {
+	r, err := dates.ParseRange(value, time.Now(), weekStart)
+	if err != nil {
+		return r, &usageError{err: fmt.Errorf("--%s: %w", name, err), cmd: cmd}
+	}
+	return r, nil
+}
+
+// addListFlags adds the flags shared by commands listing several records
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of records to show (0 = all)")
+	cmd.Flags().IntVar(&offset, "offset", 0, "Number of records to skip")
+	cmd.Flags().StringVar(&filter, "filter", "", "Only show records containing this text")
+}
+
+// render writes v to the command's output in the format chosen with --output
+func render(cmd *cobra.Command, v any) error {
+	renderer, err := output.NewRenderer(outputFormat)
+	if err != nil {
+		return &usageError{err: err, cmd: cmd}
+	}
+	return renderer.Render(cmd.OutOrStdout(), v)
+}
+
+// applyPreferences applies the settings of the config file changing how
+// results are shown, which the flags override
+func applyPreferences(cfg *config.Config) {
+	if outputFormat == "" {
+		outputFormat = cfg.Output
+	}
+	if day, err := dates.ParseWeekday(cfg.WeekStart); err == nil {
+		weekStart, weekStartSet = day, true
+	}
+	// The config file is validated when it is loaded
+	roundingIncrement, _ = config.ParseRounding(cfg.Rounding)
+	if strings.EqualFold(cfg.Theme, config.ThemePlain) {
+		styles.DisableColors()
+	}
+}
+
+// roundHours rounds a duration up to the rounding setting, then to the
+// precision of the account's time format
+func roundHours(hours float64) float64 {
+	return duration.Round(duration.RoundUp(hours, roundingIncrement), hoursFormat)
+}
+
+// activeProfile is the profile loaded by loadConfig, empty for the
+// top-level settings
+var activeProfile string
+
+// withProfile adds the active profile to the title of a prompt, so that
+// nobody logs hours to the wrong account
+func withProfile(title string) string {
+	return title + profileSuffix(activeProfile)
+}
+
+// profileSuffix names a profile at the end of a message
+func profileSuffix(profile string) string {
+	if profile == "" {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", profile)
+}
+
+// canPrompt reports whether the user can answer prompts, i.e. --no-input
+// is not set and the input and output are both terminals
+func canPrompt() bool {
+	return !noInput && isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+}
+
+// missingInput is the error of values the user would be prompted for when
+// prompts are disabled, e.g. "--project, --task"
+func missingInput(cmd *cobra.Command, what string) error {
+	return &usageError{err: fmt.Errorf("missing %s, prompts are disabled with --no-input or without a terminal", what), cmd: cmd}
+}
+
+// isTableOutput reports whether the output is meant for humans
+func isTableOutput() bool {
+	return outputFormat == "" || outputFormat == output.FormatTable
+}
+
+// Caches of the user of the client created by createAPIClient, nil when
+// there is no cache directory
+var (
+	assignmentCache *cache.Assignments
+	entryCache      *cache.Entries
+)
+
+// loadConfig loads the configuration, unlocking encrypted credentials with
+// HARVEST_PASSPHRASE or by prompting for the passphrase
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(profileName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	activeProfile = cfg.Profile
+	applyPreferences(cfg)
+	if cfg.Token != "" || cfg.TokenSource == config.TokenSourceCommand || cfg.Credentials == nil || cfg.Credentials.Sealed == nil {
+		return cfg, nil
+	}
+
+	passphrase := cfg.Passphrase
+	if passphrase == "" {
+		if !canPrompt() {
+			return nil, fmt.Errorf("Your stored credentials are encrypted, set HARVEST_PASSPHRASE to unlock them")
+		}
+		passphrase, err = ui.PasswordInput(withProfile("Unlock Harvest credentials"), "Passphrase: ")
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read passphrase: %w", err)
+		}
+	}
 
-	return api.NewClient(cfg.Token, cfg.AccountId)
+	if err := cfg.Credentials.Open(passphrase); err != nil {
+		return nil, fmt.Errorf("Failed to unlock credentials: %w", err)
+	}
+	// Kept to seal the credentials again when the tokens are refreshed
+	cfg.Passphrase = passphrase
+	cfg.Token = cfg.Credentials.Token
+	return cfg, nil
+}
+
+func createAPIClient() (*api.Client, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return newAPIClient(cfg)
+}
+
+// newAPIClient creates the client of the loaded configuration, along with
+// the caches of its user
+func newAPIClient(cfg *config.Config) (*api.Client, error) {
+	if cfg.Token == "" && cfg.TokenSource != config.TokenSourceCommand {
+		return nil, fmt.Errorf("You are not logged in, run 'harvest auth login' or set HARVEST_TOKEN")
+	}
+
+	options := api.ClientOptions{
+		BaseURL: cfg.BaseURL,
+		Timeout: time.Duration(timeout) * time.Second,
+	}
+	if path, _ := config.CredentialsPath(cfg.Profile); cfg.TokenSource == path && cfg.Credentials.RefreshToken != "" {
+		options.RefreshToken = refreshCredentials(cfg)
+	}
+	if dryRun {
+		options.DryRun = printDryRun
+	}
+	// The caches tell users apart by their token, or by the command that
+	// prints it so that reading the cache does not run the command
+	cacheKey := cfg.Token
+	if cfg.TokenSource == config.TokenSourceCommand {
+		options.LoadToken = cfg.CommandToken
+		cacheKey = config.TokenSourceCommand + ":" + cfg.TokenCommand
+	}
+
+	client, err := api.NewClient(cfg.Token, cfg.AccountId, options)
+	if err != nil {
+		return nil, err
+	}
+
+	assignmentCache, _ = cache.NewAssignments(client, cfg.AccountId, cacheKey, cache.AssignmentsOptions{})
+	entryCache, _ = cache.NewEntries(client, cfg.AccountId, cacheKey, cache.EntriesOptions{})
+	return client, nil
+}
+
+// cachedAssignments lists the user's project assignments from the on-disk
+// cache, or straight from Harvest when there is none
+func cachedAssignments(client *api.Client) ui.AssignmentLister {
+	if assignmentCache == nil {
+		return client
+	}
+	return assignmentCache
 }
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/dates"
+	"harvest-cli/internal/duration"
+	"harvest-cli/internal/ui"
+)
+
+var timerCmd = &cobra.Command{
+	Use:   "timer",
+	Short: "Track time with running timers",
+	Long:  `start, stop, restart and check the status of Harvest timers`,
+}
+
+var timerStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start a timer, stopping the one already running",
+	Long: `Start a timer, stopping the one already running. Without --project and
+--task, the ones set in the .harvest.yaml of the current directory or its
+parents are used, see 'harvest entry create --help'.`,
+	Args: cobra.NoArgs,
+	RunE: runTimerStart,
+}
+
+var timerStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the running timer",
+	Args:  cobra.NoArgs,
+	RunE:  runTimerStop,
+}
+
+var timerStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the running timer and its elapsed time",
+	Args:  cobra.NoArgs,
+	RunE:  runTimerStatus,
+}
+
+var timerRestartCmd = &cobra.Command{
+	Use:   "restart [id]",
+	Short: "Restart the timer of an entry",
+	Long: `Restart the timer of an entry, stopping the one already running.
+Without an ID, the most recently tracked entry of today is restarted.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEntryID,
+	RunE:              runTimerRestart,
+}
+
+var (
+	timerProject string
+	timerTask    string
+	timerNotes   string
+)
+
+func init() {
+	timerCmd.AddCommand(timerStartCmd)
+	timerCmd.AddCommand(timerStopCmd)
+	timerCmd.AddCommand(timerStatusCmd)
+	timerCmd.AddCommand(timerRestartCmd)
+
+	addGlobalFlags(timerCmd)
+
+	timerStartCmd.Flags().StringVarP(&timerProject, "project", "p", "", "Project ID, code, name or client/name, fuzzy matched")
+	timerStartCmd.Flags().StringVarP(&timerTask, "task", "t", "", "Task ID or name, fuzzy matched")
+	timerStartCmd.Flags().StringVar(&timerNotes, "notes", "", "Notes describing the work")
+	addAssignmentCompletion(timerStartCmd)
+}
+
+func runTimerStart(cmd *cobra.Command, args []string) error {
+	if err := api.ValidateNotes(timerNotes); err != nil {
+		return &usageError{err: err, cmd: cmd}
+	}
+
+	client, err := createAPIClient()
+	if err != nil {
+		return err
+	}
+
+	loadCompanySettings(cmd, client)
+
+	ctx := cmd.Context()
+	choice, err := chooseAssignment(cmd, newAssignmentResolver(cmd, client), timerProject, timerTask)
+	if err != nil {
+		return err
+	}
+
+	projectId, taskId := choice.ProjectId, choice.TaskId
+	if !canPrompt() {
+		var missing []string
+		if projectId == 0 {
+			missing = append(missing, "--project")
+		}
+		if taskId == 0 {
+			missing = append(missing, "--task")
+		}
+		if len(missing) > 0 {
+			return missingInput(cmd, strings.Join(missing, ", "))
+		}
+	}
+
+	if projectId == 0 {
+		selectedProject, err := ui.SelectProjectInteractively(ctx, cachedAssignments(client), 0)
+		if err != nil {
+			return fmt.Errorf("Failed to select project: %w", err)
+		}
+		projectId = selectedProject.ID
+	}
+
+	if taskId == 0 {
+		selectedTask, err := ui.SelectTaskInteractively(ctx, cachedAssignments(client), projectId, 0)
+		if err != nil {
+			return fmt.Errorf("Failed to select task: %w", err)
+		}
+		taskId = selectedTask.ID
+	}
+
+	if err := stopRunningTimer(cmd, client); err != nil {
+		return err
+	}
+
+	entry, err := client.StartTimer(ctx, api.CreateEntryRequest{
+		ProjectId: projectId,
+		TaskId:    taskId,
+		Date:      time.Now().Format(dates.Layout),
+		Notes:     timerNotes,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to start timer: %w", err)
+	}
+
+	nameDryRunEntry(cmd, client, entry)
+	if !isTableOutput() {
+		return render(cmd, entryDetail{entry})
+	}
+
+	fmt.Printf("Timer started on %s - %s.%s%s\n", entry.Project.Name, entry.Task.Name, profileSuffix(activeProfile), dryRunSuffix())
+
+	return nil
+}
+
+func runTimerStop(cmd *cobra.Command, args []string) error {
+	client, err := createAPIClient()
+	if err != nil {
+		return err
+	}
+
+	loadCompanySettings(cmd, client)
+
+	running, err := runningTimer(cmd, client)
+	if err != nil {
+		return err
+	}
+	if running == nil {
+		return fmt.Errorf("no timer is running")
+	}
+
+	entry, err := client.StopEntry(cmd.Context(), running.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to stop timer: %w", err)
+	}
+
+	if !isTableOutput() {
+		return render(cmd, entryDetail{entry})
+	}
+
+	fmt.Printf("Timer stopped on %s - %s after %s.%s\n", entry.Project.Name, entry.Task.Name, duration.FormatHours(entry.Hours, hoursFormat), dryRunSuffix())
+
+	return nil
+}
+
+func runTimerStatus(cmd *cobra.Command, args []string) error {
+	client, err := createAPIClient()
+	if err != nil {
+		return err
+	}
+
+	loadCompanySettings(cmd, client)
+
+	running, err := runningTimer(cmd, client)
+	if err != nil {
+		return err
+	}
+
+	if !isTableOutput() {
+		// Scripts get null when no timer is running
+		if running == nil {
+			return render(cmd, nil)
+		}
+		return render(cmd, entryDetail{running})
+	}
+
+	if running == nil {
+		fmt.Println("No timer is running.")
+		return nil
+	}
+
+	fmt.Printf("%s - %s: %s", running.Project.Name, running.Task.Name, duration.FormatHours(running.Hours, hoursFormat))
+	if running.TimerStartedAt != nil {
+		fmt.Printf(" (started at %s)", running.TimerStartedAt.Local().Format("15:04"))
+	}
+	fmt.Println()
+
+	return nil
+}
+
+func runTimerRestart(cmd *cobra.Command, args []string) error {
+	client, err := createAPIClient()
+	if err != nil {
+		return err
+	}
+
+	loadCompanySettings(cmd, client)
+
+	ctx := cmd.Context()
+
+	var entry *api.TimeEntry
+	if len(args) > 0 {
+		entry, err = resolveEntry(cmd, client, args)
+	} else {
+		entry, err = lastTrackedEntry(cmd, client)
+	}
+	if err != nil {
+		return err
+	}
+
+	if entry.IsRunning {
+		return fmt.Errorf("the timer of entry %d is already running", entry.ID)
+	}
+	if err := checkEntryEditable(entry); err != nil {
+		return err
+	}
+
+	if err := stopRunningTimer(cmd, client); err != nil {
+		return err
+	}
+
+	restarted, err := client.RestartEntry(ctx, entry.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to restart timer: %w", err)
+	}
+
+	if !isTableOutput() {
+		return render(cmd, entryDetail{restarted})
+	}
+
+	fmt.Printf("Timer restarted on %s - %s at %s.%s\n", restarted.Project.Name, restarted.Task.Name, duration.FormatHours(restarted.Hours, hoursFormat), dryRunSuffix())
+
+	return nil
+}
+
+// runningTimer returns the current user's running entry, or nil
+func runningTimer(cmd *cobra.Command, client *api.Client) (*api.TimeEntry, error) {
+	ctx := cmd.Context()
+
+	me, err := client.GetMe(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get current user: %w", err)
+	}
+
+	running, err := client.RunningEntry(ctx, me.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get running timer: %w", err)
+	}
+	return running, nil
+}
+
+// stopRunningTimer stops the running timer, if any, before another starts
+func stopRunningTimer(cmd *cobra.Command, client *api.Client) error {
+	running, err := runningTimer(cmd, client)
+	if err != nil || running == nil {
+		return err
+	}
+
+	stopped, err := client.StopEntry(cmd.Context(), running.ID)
+	if err != nil {
+		return fmt.Errorf("Failed to stop running timer: %w", err)
+	}
+
+	if isTableOutput() {
+		fmt.Printf("Stopped timer on %s - %s after %s.%s\n", stopped.Project.Name, stopped.Task.Name, duration.FormatHours(stopped.Hours, hoursFormat), dryRunSuffix())
+	}
+	return nil
+}
+
+// lastTrackedEntry returns the current user's most recently updated entry
+// of today
+func lastTrackedEntry(cmd *cobra.Command, client *api.Client) (*api.TimeEntry, error) {
+	ctx := cmd.Context()
+
+	me, err := client.GetMe(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get current user: %w", err)
+	}
+
+	today := time.Now().Format(dates.Layout)
+	entries, err := client.ListEntries(ctx, api.TimeEntryQuery{
+		UserId: me.ID,
+		From:   today,
+		To:     today,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list entries: %w", err)
+	}
+
+	var last *api.TimeEntry
+	for _, entry := range entries {
+		if last == nil || entry.UpdatedAt.After(last.UpdatedAt) {
+			last = entry
+		}
+	}
+	if last == nil {
+		return nil, fmt.Errorf("no entry tracked today, pass the ID of the entry to restart")
+	}
+	return last, nil
+}
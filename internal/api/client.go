@@ -1,30 +1,127 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
+// DefaultBaseURL is the root of the Harvest v2 API
+const DefaultBaseURL = "https://api.harvestapp.com/v2/"
+
+// ClientOptions configures the API client
+type ClientOptions struct {
+	// BaseURL overrides the Harvest API root, e.g. to point at a fake server
+	BaseURL string
+	// Timeout bounds each HTTP request, including reading the response
+	Timeout time.Duration
+	// Retry controls how rate limited and failed requests are retried
+	Retry RetryPolicy
+	// RateLimit is the number of requests allowed per RateWindow. The client
+	// waits before sending a request that would exceed it.
+	RateLimit  int
+	RateWindow time.Duration
+	// RefreshToken is called when Harvest rejects the token, e.g. once an
+	// OAuth2 access token expired. The request is sent again once with the
+	// token it returns.
+	RefreshToken func(ctx context.Context) (string, error)
+	// LoadToken provides the token when NewClient is given none. It is
+	// called before the first request, so that e.g. a password manager is
+	// only asked when Harvest is contacted.
+	LoadToken func(ctx context.Context) (string, error)
+	// DryRun receives the requests that would change data instead of
+	// Harvest. They return a synthetic result, built from the request and
+	// the record it changes, which is still read.
+	DryRun func(request DryRunRequest)
+}
+
 type Client struct {
-	token    string
-	accountId     string
+	token      string
+	accountId  string
+	baseURL    string
 	httpClient *http.Client
+	retry      RetryPolicy
+	limiter    *rateLimiter
+
+	// tokenMu guards token, which changes when it is loaded or refreshed
+	tokenMu      sync.Mutex
+	loadToken    func(ctx context.Context) (string, error)
+	refreshToken func(ctx context.Context) (string, error)
+	dryRun       func(request DryRunRequest)
 }
 
-func NewClient(token, accountid string) (*Client, error) {
-	if token == "" {
+func NewClient(token, accountid string, options ClientOptions) (*Client, error) {
+	if token == "" && options.LoadToken == nil {
 		return nil, fmt.Errorf("Harvest token is required")
 	}
 	if accountid == "" {
 		return nil, fmt.Errorf("Harvest account id is required")
 	}
 
+	// Set defaults
+	if options.BaseURL == "" {
+		options.BaseURL = DefaultBaseURL
+	}
+	if !strings.HasSuffix(options.BaseURL, "/") {
+		options.BaseURL += "/"
+	}
+	if options.Timeout == 0 {
+		options.Timeout = 30 * time.Second
+	}
+	if options.RateLimit == 0 {
+		options.RateLimit = DefaultRateLimit
+	}
+	if options.RateWindow == 0 {
+		options.RateWindow = DefaultRateWindow
+	}
+
 	return &Client{
-		token: token,
-		accountId:  accountid,
+		token:     token,
+		accountId: accountid,
+		baseURL:   options.BaseURL,
 		httpClient: &http.Client{
-			Timeout: time.Duration(30) * time.Second,
+			Timeout: options.Timeout,
 		},
+		retry:        options.Retry.withDefaults(),
+		limiter:      newRateLimiter(options.RateLimit, options.RateWindow),
+		loadToken:    options.LoadToken,
+		refreshToken: options.RefreshToken,
+		dryRun:       options.DryRun,
 	}, nil
 }
+
+// currentToken returns the token sent with requests, loading it the first
+// time when it was not given
+func (c *Client) currentToken(ctx context.Context) (string, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.token == "" {
+		token, err := c.loadToken(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to load the token: %w", err)
+		}
+		c.token = token
+	}
+	return c.token, nil
+}
+
+// refresh replaces the rejected token, unless a concurrent request already
+// did
+func (c *Client) refresh(ctx context.Context, rejected string) error {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.token != rejected {
+		return nil
+	}
+	token, err := c.refreshToken(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to refresh the access token: %w", err)
+	}
+	c.token = token
+	return nil
+}
@@ -0,0 +1,193 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/api/fake"
+)
+
+// newTestClient returns a client of the fake server retrying without delay
+func newTestClient(t *testing.T, srv *fake.Server, options api.ClientOptions) *api.Client {
+	t.Helper()
+	options.BaseURL = srv.BaseURL()
+	options.Retry = api.RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	client, err := api.NewClient(srv.Token, srv.AccountID, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestEntryLifecycle(t *testing.T) {
+	srv := fake.NewServer()
+	defer srv.Close()
+	// Small pages so that listing follows the pagination
+	srv.PerPage = 2
+	assignment := srv.AddProject(api.ClientData{Name: "ACME"}, api.Project{Name: "Website"}, api.Task{Name: "Design"}, api.Task{Name: "QA"})
+	design, qa := assignment.TaskAssignments[0].Task, assignment.TaskAssignments[1].Task
+
+	ctx := context.Background()
+	client := newTestClient(t, srv, api.ClientOptions{})
+
+	var ids []int64
+	for _, date := range []string{"2024-05-01", "2024-05-02", "2024-05-03", "2024-05-04", "2024-05-05"} {
+		entry, err := client.CreateEntry(ctx, api.CreateEntryRequest{
+			ProjectId: assignment.Project.ID,
+			TaskId:    design.ID,
+			Date:      date,
+			Hours:     1.5,
+			Notes:     "Mockups",
+		})
+		if err != nil {
+			t.Fatalf("CreateEntry(%s): %v", date, err)
+		}
+		if entry.Project.Name != "Website" || entry.Task.Name != "Design" || entry.Hours != 1.5 {
+			t.Errorf("CreateEntry(%s) = %s/%s %v hours, want Website/Design 1.5 hours", date, entry.Project.Name, entry.Task.Name, entry.Hours)
+		}
+		ids = append(ids, entry.ID)
+	}
+
+	entries, err := client.ListEntries(ctx, api.TimeEntryQuery{})
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("ListEntries returned %d entries over 3 pages, want 5", len(entries))
+	}
+	if entries[0].SpentDate != "2024-05-05" || entries[4].SpentDate != "2024-05-01" {
+		t.Errorf("ListEntries dates = %s..%s, want newest first", entries[0].SpentDate, entries[4].SpentDate)
+	}
+
+	filtered, err := client.ListEntries(ctx, api.TimeEntryQuery{From: "2024-05-02", To: "2024-05-04"})
+	if err != nil {
+		t.Fatalf("ListEntries with a range: %v", err)
+	}
+	if len(filtered) != 3 {
+		t.Errorf("ListEntries from 2024-05-02 to 2024-05-04 returned %d entries, want 3", len(filtered))
+	}
+
+	hours, notes := 2.25, "Review"
+	updated, err := client.UpdateEntry(ctx, ids[0], api.UpdateEntryRequest{TaskId: &qa.ID, Hours: &hours, Notes: &notes})
+	if err != nil {
+		t.Fatalf("UpdateEntry: %v", err)
+	}
+	if updated.Task.Name != "QA" || updated.Hours != 2.25 || updated.Notes == nil || *updated.Notes != "Review" {
+		t.Errorf("UpdateEntry = %s %v hours %v, want QA 2.25 hours Review", updated.Task.Name, updated.Hours, updated.Notes)
+	}
+
+	if err := client.DeleteEntry(ctx, ids[1]); err != nil {
+		t.Fatalf("DeleteEntry: %v", err)
+	}
+	_, err = client.GetEntry(ctx, ids[1])
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || !apiErr.IsNotFound() {
+		t.Errorf("GetEntry of a deleted entry returned %v, want a not found error", err)
+	}
+
+	entries, err = client.ListEntries(ctx, api.TimeEntryQuery{})
+	if err != nil {
+		t.Fatalf("ListEntries after delete: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Errorf("ListEntries after delete returned %d entries, want 4", len(entries))
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		failures []int
+		// wantStatus is the status of the error returned, 0 for success
+		wantStatus int
+	}{
+		{name: "GET after a server error", method: http.MethodGet, failures: []int{503}},
+		{name: "GET after two rate limits", method: http.MethodGet, failures: []int{429, 429}},
+		{name: "GET gives up after the retries", method: http.MethodGet, failures: []int{500, 500, 500, 500, 500}, wantStatus: 500},
+		{name: "POST is not resent after a server error", method: http.MethodPost, failures: []int{502}, wantStatus: 502},
+		{name: "POST after a rate limit", method: http.MethodPost, failures: []int{429}},
+		{name: "PATCH after a rate limit", method: http.MethodPatch, failures: []int{429}},
+		{name: "PATCH is not resent after a server error", method: http.MethodPatch, failures: []int{503}, wantStatus: 503},
+		{name: "DELETE after a server error", method: http.MethodDelete, failures: []int{500}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fake.NewServer()
+			defer srv.Close()
+			assignment := srv.AddProject(api.ClientData{Name: "ACME"}, api.Project{Name: "Website"}, api.Task{Name: "Design"})
+			ctx := context.Background()
+			client := newTestClient(t, srv, api.ClientOptions{})
+
+			request := api.CreateEntryRequest{ProjectId: assignment.Project.ID, TaskId: assignment.TaskAssignments[0].Task.ID, Date: "2024-05-01", Hours: 1}
+			existing, err := client.CreateEntry(ctx, request)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, status := range tt.failures {
+				srv.FailNext(status, 0)
+			}
+			switch tt.method {
+			case http.MethodGet:
+				_, err = client.GetEntry(ctx, existing.ID)
+			case http.MethodPost:
+				_, err = client.CreateEntry(ctx, request)
+			case http.MethodPatch:
+				hours := 2.0
+				_, err = client.UpdateEntry(ctx, existing.ID, api.UpdateEntryRequest{Hours: &hours})
+			case http.MethodDelete:
+				err = client.DeleteEntry(ctx, existing.ID)
+			}
+
+			var apiErr *api.Error
+			switch {
+			case tt.wantStatus == 0 && err != nil:
+				t.Fatalf("%s failed: %v", tt.method, err)
+			case tt.wantStatus != 0 && (!errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus):
+				t.Fatalf("%s returned %v, want a %d error", tt.method, err, tt.wantStatus)
+			}
+
+			wantEntries := 1
+			switch {
+			case tt.method == http.MethodDelete:
+				wantEntries = 0
+			case tt.method == http.MethodPost && tt.wantStatus == 0:
+				wantEntries = 2
+			}
+			entries := srv.Entries()
+			if len(entries) != wantEntries {
+				t.Fatalf("the server has %d entries, want %d", len(entries), wantEntries)
+			}
+			if tt.method == http.MethodPatch {
+				wantHours := 2.0
+				if tt.wantStatus != 0 {
+					wantHours = 1
+				}
+				if entries[0].Hours != wantHours {
+					t.Errorf("the entry has %v hours, want %v", entries[0].Hours, wantHours)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	srv := fake.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, api.ClientOptions{})
+
+	srv.FailNext(http.StatusTooManyRequests, time.Second)
+	start := time.Now()
+	if _, err := client.GetMe(context.Background()); err != nil {
+		t.Fatalf("GetMe: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("the request was resent after %v, before the Retry-After of 1s", elapsed)
+	}
+}
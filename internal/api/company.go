@@ -0,0 +1,24 @@
+package api
+
+import "context"
+
+// Company holds the account settings that affect how time is entered
+type Company struct {
+	Name string `json:"name"`
+	// TimeFormat is "decimal" or "hours_minutes"
+	TimeFormat string `json:"time_format"`
+	// WeekStartDay is the first day of the week, e.g. "Monday"
+	WeekStartDay         string `json:"week_start_day"`
+	WantsTimestampTimers bool   `json:"wants_timestamp_timers"`
+	WeeklyCapacity       int    `json:"weekly_capacity"`
+}
+
+// GetCompany returns the settings of the account the client is bound to
+func (c *Client) GetCompany(ctx context.Context) (*Company, error) {
+	var company Company
+	err := c.makeRequest(ctx, "GET", "/company", nil, &company)
+	if err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
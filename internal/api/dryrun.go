@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// RedactedToken replaces the token in the requests shown by a dry run
+const RedactedToken = "[REDACTED]"
+
+// DryRunRequest is a request that was not sent because of a dry run
+type DryRunRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// isMutating reports whether a method changes data, so is skipped by a dry
+// run
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// simulate hands a request to the dry run instead of sending it, then fills
+// result as Harvest would: the record of an update is read as it is now and
+// the body applied on top of it, the one of a creation is the body alone,
+// running when it is a time entry without hours, i.e. a timer
+func (c *Client) simulate(ctx context.Context, method, url string, jsonBody []byte, result interface{}) error {
+	c.dryRun(DryRunRequest{
+		Method: method,
+		URL:    url,
+		Headers: map[string]string{
+			"Authorization":      "Bearer " + RedactedToken,
+			"Harvest-Account-Id": c.accountId,
+		},
+		Body: jsonBody,
+	})
+
+	if result == nil {
+		return nil
+	}
+
+	record := map[string]json.RawMessage{}
+	if method != http.MethodPost {
+		resource, action := recordURL(url)
+		if err := c.makeRequest(ctx, http.MethodGet, resource, nil, result); err != nil {
+			return fmt.Errorf("failed to read the record of the dry run: %w", err)
+		}
+		current, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to build the result of the dry run: %w", err)
+		}
+		if err := json.Unmarshal(current, &record); err != nil {
+			return fmt.Errorf("failed to build the result of the dry run: %w", err)
+		}
+		// Timers are the only actions on records
+		switch action {
+		case "stop":
+			record["is_running"] = json.RawMessage("false")
+		case "restart":
+			record["is_running"] = json.RawMessage("true")
+		}
+	}
+
+	if jsonBody != nil {
+		if err := applyBody(record, jsonBody); err != nil {
+			return fmt.Errorf("failed to build the result of the dry run: %w", err)
+		}
+	}
+	if method == http.MethodPost && path.Base(url) == "time_entries" {
+		_, hours := record["hours"]
+		record["is_running"] = json.RawMessage(strconv.FormatBool(!hours))
+	}
+
+	synthetic, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to build the result of the dry run: %w", err)
+	}
+	// Start over so that replaced objects do not keep their former fields
+	reflect.ValueOf(result).Elem().SetZero()
+	if err := json.Unmarshal(synthetic, result); err != nil {
+		return fmt.Errorf("failed to build the result of the dry run: %w", err)
+	}
+	return nil
+}
+
+// recordURL splits the action off a URL like time_entries/1/stop, returning
+// the URL of the record and the action
+func recordURL(url string) (string, string) {
+	last := path.Base(url)
+	if _, err := strconv.ParseInt(last, 10, 64); err == nil {
+		return url, ""
+	}
+	return strings.TrimSuffix(url, "/"+last), last
+}
+
+// applyBody sets the fields of a request body on a record. References
+// like "project_id": 1 become the objects of the record, "project": {"id": 1},
+// their other fields being unknown unless the reference did not change.
+func applyBody(record map[string]json.RawMessage, jsonBody []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(jsonBody, &fields); err != nil {
+		return err
+	}
+	for key, value := range fields {
+		record[key] = value
+		if name, ok := strings.CutSuffix(key, "_id"); ok {
+			if _, taken := fields[name]; taken {
+				continue
+			}
+			var current struct {
+				ID json.RawMessage `json:"id"`
+			}
+			if json.Unmarshal(record[name], &current) == nil && string(current.ID) == string(value) {
+				continue
+			}
+			record[name], _ = json.Marshal(map[string]json.RawMessage{"id": value})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/api/fake"
+)
+
+func TestDryRun(t *testing.T) {
+	srv := fake.NewServer()
+	defer srv.Close()
+	assignment := srv.AddProject(api.ClientData{Name: "ACME"}, api.Project{Name: "Website"}, api.Task{Name: "Design"}, api.Task{Name: "QA"})
+	projectId := assignment.Project.ID
+	design, qa := assignment.TaskAssignments[0].Task, assignment.TaskAssignments[1].Task
+
+	ctx := context.Background()
+	client := newTestClient(t, srv, api.ClientOptions{})
+	logged, err := client.CreateEntry(ctx, api.CreateEntryRequest{ProjectId: projectId, TaskId: design.ID, Date: "2024-05-01", Hours: 1, Notes: "Mockups"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	running, err := client.StartTimer(ctx, api.CreateEntryRequest{ProjectId: projectId, TaskId: design.ID, Date: "2024-05-02"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests []api.DryRunRequest
+	dryRun := newTestClient(t, srv, api.ClientOptions{
+		DryRun: func(request api.DryRunRequest) { requests = append(requests, request) },
+	})
+
+	hours, notes := 2.5, "Review"
+	tests := []struct {
+		name       string
+		call       func() (*api.TimeEntry, error)
+		wantMethod string
+		wantURL    string
+		wantBody   string
+		// check inspects the synthetic result
+		check func(t *testing.T, entry *api.TimeEntry)
+	}{
+		{
+			name: "create",
+			call: func() (*api.TimeEntry, error) {
+				return dryRun.CreateEntry(ctx, api.CreateEntryRequest{ProjectId: projectId, TaskId: qa.ID, Date: "2024-05-03", Hours: 1.5, Notes: "Tests"})
+			},
+			wantMethod: http.MethodPost,
+			wantURL:    "time_entries",
+			wantBody:   `{"project_id":PROJECT,"task_id":QA,"spent_date":"2024-05-03","hours":1.5,"notes":"Tests"}`,
+			check: func(t *testing.T, entry *api.TimeEntry) {
+				if entry.ID != 0 || entry.Project.ID != projectId || entry.Task.ID != qa.ID || entry.SpentDate != "2024-05-03" ||
+					entry.Hours != 1.5 || entry.Notes == nil || *entry.Notes != "Tests" || entry.IsRunning {
+					t.Errorf("got %+v, want the body of the request", entry)
+				}
+			},
+		},
+		{
+			name: "start timer",
+			call: func() (*api.TimeEntry, error) {
+				return dryRun.StartTimer(ctx, api.CreateEntryRequest{ProjectId: projectId, TaskId: qa.ID, Date: "2024-05-03"})
+			},
+			wantMethod: http.MethodPost,
+			wantURL:    "time_entries",
+			wantBody:   `{"project_id":PROJECT,"task_id":QA,"spent_date":"2024-05-03"}`,
+			check: func(t *testing.T, entry *api.TimeEntry) {
+				if !entry.IsRunning || entry.Hours != 0 {
+					t.Errorf("got a timer running %v with %v hours, want a running timer", entry.IsRunning, entry.Hours)
+				}
+			},
+		},
+		{
+			name: "update",
+			call: func() (*api.TimeEntry, error) {
+				return dryRun.UpdateEntry(ctx, logged.ID, api.UpdateEntryRequest{ProjectId: &projectId, TaskId: &qa.ID, Hours: &hours, Notes: &notes})
+			},
+			wantMethod: http.MethodPatch,
+			wantURL:    "time_entries/LOGGED",
+			wantBody:   `{"project_id":PROJECT,"task_id":QA,"hours":2.5,"notes":"Review"}`,
+			check: func(t *testing.T, entry *api.TimeEntry) {
+				if entry.ID != logged.ID || entry.SpentDate != "2024-05-01" || entry.Client.Name != "ACME" {
+					t.Errorf("got entry %d of %s for %s, want the unchanged fields of entry %d", entry.ID, entry.SpentDate, entry.Client.Name, logged.ID)
+				}
+				if entry.Hours != 2.5 || entry.Notes == nil || *entry.Notes != "Review" {
+					t.Errorf("got %v hours and notes %v, want the ones of the request", entry.Hours, entry.Notes)
+				}
+				// The project did not change so it keeps its name, the task did
+				if entry.Project.Name != "Website" || entry.Task != (api.Task{ID: qa.ID}) {
+					t.Errorf("got project %+v and task %+v, want Website and task %d alone", entry.Project, entry.Task, qa.ID)
+				}
+			},
+		},
+		{
+			name:       "stop",
+			call:       func() (*api.TimeEntry, error) { return dryRun.StopEntry(ctx, running.ID) },
+			wantMethod: http.MethodPatch,
+			wantURL:    "time_entries/RUNNING/stop",
+			check: func(t *testing.T, entry *api.TimeEntry) {
+				if entry.ID != running.ID || entry.IsRunning {
+					t.Errorf("got entry %d running %v, want entry %d stopped", entry.ID, entry.IsRunning, running.ID)
+				}
+			},
+		},
+		{
+			name:       "restart",
+			call:       func() (*api.TimeEntry, error) { return dryRun.RestartEntry(ctx, logged.ID) },
+			wantMethod: http.MethodPatch,
+			wantURL:    "time_entries/LOGGED/restart",
+			check: func(t *testing.T, entry *api.TimeEntry) {
+				if entry.ID != logged.ID || !entry.IsRunning {
+					t.Errorf("got entry %d running %v, want entry %d running", entry.ID, entry.IsRunning, logged.ID)
+				}
+			},
+		},
+		{
+			name:       "delete",
+			call:       func() (*api.TimeEntry, error) { return nil, dryRun.DeleteEntry(ctx, logged.ID) },
+			wantMethod: http.MethodDelete,
+			wantURL:    "time_entries/LOGGED",
+		},
+	}
+
+	replacer := strings.NewReplacer(
+		"PROJECT", formatID(projectId),
+		"QA", formatID(qa.ID),
+		"LOGGED", formatID(logged.ID),
+		"RUNNING", formatID(running.ID),
+	)
+	before := srv.Entries()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			entry, err := tt.call()
+			if err != nil {
+				t.Fatalf("the dry run failed: %v", err)
+			}
+
+			if len(requests) != 1 {
+				t.Fatalf("got %d requests in the dry run, want 1", len(requests))
+			}
+			request := requests[0]
+			if wantURL := srv.BaseURL() + replacer.Replace(tt.wantURL); request.Method != tt.wantMethod || request.URL != wantURL {
+				t.Errorf("got %s %s, want %s %s", request.Method, request.URL, tt.wantMethod, wantURL)
+			}
+			if wantBody := replacer.Replace(tt.wantBody); string(request.Body) != wantBody {
+				t.Errorf("got body %s, want %s", request.Body, wantBody)
+			}
+
+			if request.Headers["Authorization"] != "Bearer "+api.RedactedToken {
+				t.Errorf("got Authorization %q, want the token redacted", request.Headers["Authorization"])
+			}
+			shown, _ := json.Marshal(request)
+			if strings.Contains(string(shown), srv.Token) {
+				t.Errorf("the token shows in %s", shown)
+			}
+
+			if tt.check != nil {
+				tt.check(t, entry)
+			}
+		})
+	}
+
+	after := srv.Entries()
+	if len(after) != len(before) {
+		t.Fatalf("the server has %d entries after the dry runs, want %d", len(after), len(before))
+	}
+	for i := range before {
+		// The running timer's hours grow while the tests run
+		after[i].Hours, before[i].Hours = 0, 0
+		after[i].RoundedHours, before[i].RoundedHours = 0, 0
+		b, _ := json.Marshal(before[i])
+		a, _ := json.Marshal(after[i])
+		if string(a) != string(b) {
+			t.Errorf("entry %d changed in the dry runs:\n%s\nwant\n%s", before[i].ID, a, b)
+		}
+	}
+}
+
+func TestDryRunReads(t *testing.T) {
+	srv := fake.NewServer()
+	defer srv.Close()
+	client := newTestClient(t, srv, api.ClientOptions{
+		DryRun: func(request api.DryRunRequest) {
+			t.Errorf("got %s %s in the dry run, want it sent", request.Method, request.URL)
+		},
+	})
+
+	me, err := client.GetMe(context.Background())
+	if err != nil {
+		t.Fatalf("GetMe: %v", err)
+	}
+	if me.Name != "Fake User" {
+		t.Errorf("GetMe = %q, want the user of the server", me.Name)
+	}
+}
+
+func formatID(id int64) string {
+	b, _ := json.Marshal(id)
+	return string(b)
+}
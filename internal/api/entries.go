@@ -1,66 +1,69 @@
 package api
 
 import (
+	"context"
 	"fmt"
-	"net/url"
-	"strconv"
+	"iter"
+	"unicode/utf8"
 )
 
-func (c *Client) CreateEntry(req CreateEntryRequest) (*CreateEntryResponse, error) {
-	var response CreateEntryResponse
-	err := c.makeRequest("POST", "/time_entries", req, &response)
+// MaxNotesLength is the longest notes Harvest accepts on a time entry
+const MaxNotesLength = 2000
+
+// ValidateNotes checks notes against Harvest's length limit before they
+// are sent
+func ValidateNotes(notes string) error {
+	if length := utf8.RuneCountInString(notes); length > MaxNotesLength {
+		return fmt.Errorf("notes are %d characters long, Harvest allows at most %d", length, MaxNotesLength)
+	}
+	return nil
+}
+
+func (c *Client) CreateEntry(ctx context.Context, req CreateEntryRequest) (*TimeEntry, error) {
+	var entry TimeEntry
+	err := c.makeRequest(ctx, "POST", "/time_entries", req, &entry)
 	if err != nil {
 		return nil, err
 	}
-	return &response, nil
+	return &entry, nil
 }
 
-func (c *Client) GetEntry(id string) (*Entry, error) {
-	var entry Entry
-	endpoint := fmt.Sprintf("/time_entries/%s", id)
-	err := c.makeRequest("GET", endpoint, nil, &entry)
+func (c *Client) GetEntry(ctx context.Context, id int64) (*TimeEntry, error) {
+	var entry TimeEntry
+	endpoint := fmt.Sprintf("/time_entries/%d", id)
+	err := c.makeRequest(ctx, "GET", endpoint, nil, &entry)
 	if err != nil {
 		return nil, err
 	}
 	return &entry, nil
 }
 
-func (c *Client) ListEntries(params ListParams) ([]*Entry, error) {
-	queryParams := url.Values{}
-	if params.Limit > 0 {
-		queryParams.Set("limit", strconv.Itoa(params.Limit))
-	}
-	if params.Offset > 0 {
-		queryParams.Set("offset", strconv.Itoa(params.Offset))
-	}
-	if params.Filter != "" {
-		queryParams.Set("filter", params.Filter)
-	}
+// Entries returns an iterator over every time entry matching query,
+// following Harvest's pagination as it goes
+func (c *Client) Entries(ctx context.Context, query TimeEntryQuery) iter.Seq2[*TimeEntry, error] {
+	endpoint := withQuery("/time_entries", query.values())
 
-	endpoint := "/entries"
-	if len(queryParams) > 0 {
-		endpoint += "?" + queryParams.Encode()
-	}
+	return paginate(ctx, c, endpoint, func() page[*TimeEntry] {
+		return &ListEntriesResponse{}
+	})
+}
 
-	var response ListEntriesResponse
-	err := c.makeRequest("GET", endpoint, nil, &response)
-	if err != nil {
-		return nil, err
-	}
-	return response.Entries, nil
+// ListEntries returns every time entry matching query
+func (c *Client) ListEntries(ctx context.Context, query TimeEntryQuery) ([]*TimeEntry, error) {
+	return collect(c.Entries(ctx, query))
 }
 
-func (c *Client) UpdateEntry(id string, req UpdateEntryRequest) (*Entry, error) {
-	var entry Entry
-	endpoint := fmt.Sprintf("/time_entries/%s", id)
-	err := c.makeRequest("PUT", endpoint, req, &entry)
+func (c *Client) UpdateEntry(ctx context.Context, id int64, req UpdateEntryRequest) (*TimeEntry, error) {
+	var entry TimeEntry
+	endpoint := fmt.Sprintf("/time_entries/%d", id)
+	err := c.makeRequest(ctx, "PATCH", endpoint, req, &entry)
 	if err != nil {
 		return nil, err
 	}
 	return &entry, nil
 }
 
-func (c *Client) DeleteEntry(id string) error {
-	endpoint := fmt.Sprintf("/entries/%s", id)
-	return c.makeRequest("DELETE", endpoint, nil, nil)
+func (c *Client) DeleteEntry(ctx context.Context, id int64) error {
+	endpoint := fmt.Sprintf("/time_entries/%d", id)
+	return c.makeRequest(ctx, "DELETE", endpoint, nil, nil)
 }
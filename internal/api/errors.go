@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Error is returned when Harvest answers with a non-2xx status
+type Error struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Code and Description come from the OAuth style error body Harvest
+	// sends for authentication failures, e.g. "invalid_token"
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+	// Message is the human readable reason sent with validation errors
+	Message string `json:"message"`
+	// Method and Path identify the failed request
+	Method string
+	Path   string
+	// RetryAfter is how long Harvest asked to wait, if it did
+	RetryAfter time.Duration
+	// Body is the raw response body, kept for bodies that are not JSON
+	Body string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Reason())
+}
+
+// Reason returns the most specific explanation Harvest gave for the failure
+func (e *Error) Reason() string {
+	switch {
+	case e.Message != "":
+		return e.Message
+	case e.Description != "":
+		return e.Description
+	case e.Code != "":
+		return e.Code
+	case e.Body != "":
+		return e.Body
+	}
+	return http.StatusText(e.StatusCode)
+}
+
+// Retryable reports whether sending the same request later may succeed
+func (e *Error) Retryable() bool {
+	return isRetryableStatus(e.StatusCode)
+}
+
+// IsUnauthorized reports whether the credentials were missing or rejected
+func (e *Error) IsUnauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether the user lacks access to the resource
+func (e *Error) IsForbidden() bool {
+	return e.StatusCode == http.StatusForbidden
+}
+
+// IsNotFound reports whether the resource does not exist
+func (e *Error) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsValidation reports whether Harvest rejected the request payload
+func (e *Error) IsValidation() bool {
+	return e.StatusCode == http.StatusUnprocessableEntity || e.StatusCode == http.StatusBadRequest
+}
+
+// IsRateLimited reports whether the request budget was exhausted
+func (e *Error) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// AsError returns the *Error wrapped in err, if any
+func AsError(err error) (*Error, bool) {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
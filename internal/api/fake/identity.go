@@ -0,0 +1,172 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"harvest-cli/internal/api"
+)
+
+const (
+	// DefaultClientID is the OAuth2 client id accepted by a new identity server
+	DefaultClientID = "fake-client-id"
+	// DefaultClientSecret is the OAuth2 client secret accepted by a new
+	// identity server
+	DefaultClientSecret = "fake-client-secret"
+)
+
+// Identity is a stand-in for Harvest ID, the OAuth2 server of Harvest. It
+// approves every authorization without asking and the access tokens it
+// issues are the ones the linked fake Harvest API accepts:
+//
+//	srv := fake.NewServer()
+//	id := fake.NewIdentity(srv)
+//	oauth, _ := api.NewOAuth(id.ClientID, id.ClientSecret, api.OAuthOptions{IdentityURL: id.URL})
+//	token, _ := oauth.Authorize(ctx, func(page string) error { go http.Get(page); return nil })
+type Identity struct {
+	*httptest.Server
+
+	// ClientID and ClientSecret identify the OAuth2 application
+	ClientID     string
+	ClientSecret string
+
+	harvest *Server
+
+	mu            sync.Mutex
+	accounts      []api.Account
+	codes         map[string]bool
+	refreshTokens map[string]bool
+	issued        int
+}
+
+// NewIdentity starts a fake Harvest ID issuing tokens for harvest, with the
+// Harvest account of harvest and a Forecast account
+func NewIdentity(harvest *Server) *Identity {
+	accountId, _ := strconv.ParseInt(harvest.AccountID, 10, 64)
+	i := &Identity{
+		ClientID:     DefaultClientID,
+		ClientSecret: DefaultClientSecret,
+		harvest:      harvest,
+		accounts: []api.Account{
+			{ID: accountId, Name: "Fake Company", Product: "harvest"},
+			{ID: accountId + 1, Name: "Fake Company", Product: "forecast"},
+		},
+		codes:         make(map[string]bool),
+		refreshTokens: make(map[string]bool),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /oauth2/authorize", i.handleAuthorize)
+	mux.HandleFunc("POST /api/v2/oauth2/token", i.handleToken)
+	mux.HandleFunc("GET /api/v2/accounts", i.handleAccounts)
+
+	i.Server = httptest.NewServer(mux)
+	return i
+}
+
+// AddAccount adds an account the user can choose at login
+func (i *Identity) AddAccount(account api.Account) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.accounts = append(i.accounts, account)
+}
+
+// Expire makes the Harvest API reject the access token issued last, as
+// when it expired, until it is refreshed
+func (i *Identity) Expire() {
+	i.harvest.SetToken("expired")
+}
+
+func (i *Identity) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != i.ClientID {
+		writeError(w, http.StatusBadRequest, "invalid_client", "Unknown client_id.")
+		return
+	}
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirect.Host == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "redirect_uri is invalid.")
+		return
+	}
+
+	i.mu.Lock()
+	i.issued++
+	code := fmt.Sprintf("code-%d", i.issued)
+	i.codes[code] = true
+	i.mu.Unlock()
+
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirect.RawQuery = values.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (i *Identity) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	if r.PostForm.Get("client_id") != i.ClientID || r.PostForm.Get("client_secret") != i.ClientSecret {
+		writeError(w, http.StatusUnauthorized, "invalid_client", "Client authentication failed.")
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	// Codes and refresh tokens are single use, like Harvest ID's
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		code := r.PostForm.Get("code")
+		if !i.codes[code] {
+			writeError(w, http.StatusBadRequest, "invalid_grant", "The authorization code is invalid.")
+			return
+		}
+		delete(i.codes, code)
+	case "refresh_token":
+		refreshToken := r.PostForm.Get("refresh_token")
+		if !i.refreshTokens[refreshToken] {
+			writeError(w, http.StatusBadRequest, "invalid_grant", "The refresh token is invalid.")
+			return
+		}
+		delete(i.refreshTokens, refreshToken)
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported_grant_type", "The grant type is not supported.")
+		return
+	}
+
+	i.issued++
+	token := api.OAuthToken{
+		AccessToken:  fmt.Sprintf("access-%d", i.issued),
+		RefreshToken: fmt.Sprintf("refresh-%d", i.issued),
+		TokenType:    "bearer",
+		ExpiresIn:    1209600,
+	}
+	i.refreshTokens[token.RefreshToken] = true
+	i.harvest.SetToken(token.AccessToken)
+
+	writeJSON(w, http.StatusOK, token)
+}
+
+func (i *Identity) handleAccounts(w http.ResponseWriter, r *http.Request) {
+	i.harvest.mu.Lock()
+	token, user := i.harvest.Token, i.harvest.user
+	i.harvest.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+token {
+		writeError(w, http.StatusUnauthorized, "invalid_token", "The access token is invalid.")
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	writeJSON(w, http.StatusOK, api.AccountsResponse{
+		User:     user,
+		Accounts: i.accounts,
+	})
+}
@@ -0,0 +1,813 @@
+// Package fake provides an in-memory stand-in for the Harvest v2 API.
+//
+// It is meant for exercising the CLI end to end without network access:
+//
+//	srv := fake.NewServer()
+//	defer srv.Close()
+//	project := srv.AddProject(api.ClientData{Name: "ACME"}, api.Project{Name: "Website"}, api.Task{Name: "Design"})
+//	client, _ := api.NewClient(srv.Token, srv.AccountID, api.ClientOptions{BaseURL: srv.BaseURL()})
+//
+// Setting HARVEST_BASE_URL, HARVEST_TOKEN and HARVEST_ACCOUNT_ID to the
+// server's values points the harvest commands at it. NewIdentity adds a
+// stand-in for Harvest ID to exercise the OAuth2 login.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"harvest-cli/internal/api"
+)
+
+// clockLayout is the format of started_time and ended_time, e.g. "8:00am"
+const clockLayout = "3:04pm"
+
+const (
+	// DefaultToken is the personal access token accepted by a new server
+	DefaultToken = "fake-token"
+	// DefaultAccountID is the account id accepted by a new server
+	DefaultAccountID = "123456"
+)
+
+// Server is an httptest based Harvest API keeping its state in memory
+type Server struct {
+	*httptest.Server
+
+	// Token and AccountID are the credentials requests must carry
+	Token     string
+	AccountID string
+	// PerPage is the page size used by list endpoints
+	PerPage int
+
+	mu                 sync.Mutex
+	company            api.Company
+	user               api.User
+	projectAssignments []*api.ProjectAssignment
+	entries            map[int64]*api.TimeEntry
+	nextID             int64
+	failures           []failure
+}
+
+// failure is a canned error response returned instead of handling a request
+type failure struct {
+	status     int
+	retryAfter time.Duration
+}
+
+// NewServer starts a fake Harvest API with a single user and no data
+func NewServer() *Server {
+	s := &Server{
+		Token:     DefaultToken,
+		AccountID: DefaultAccountID,
+		PerPage:   100,
+		company: api.Company{
+			Name:           "Fake Company",
+			TimeFormat:     "decimal",
+			WeekStartDay:   "Monday",
+			WeeklyCapacity: 126000,
+		},
+		user:    api.User{ID: 1, Name: "Fake User", Email: "fake.user@example.com"},
+		entries: make(map[int64]*api.TimeEntry),
+		nextID:  1,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v2/company", s.handleGetCompany)
+	mux.HandleFunc("GET /v2/users/me", s.handleGetMe)
+	mux.HandleFunc("GET /v2/users/me/project_assignments", s.handleListProjectAssignments)
+	mux.HandleFunc("GET /v2/time_entries", s.handleListEntries)
+	mux.HandleFunc("POST /v2/time_entries", s.handleCreateEntry)
+	mux.HandleFunc("GET /v2/time_entries/{id}", s.handleGetEntry)
+	mux.HandleFunc("PATCH /v2/time_entries/{id}", s.handleUpdateEntry)
+	mux.HandleFunc("DELETE /v2/time_entries/{id}", s.handleDeleteEntry)
+	mux.HandleFunc("PATCH /v2/time_entries/{id}/stop", s.handleStopEntry)
+	mux.HandleFunc("PATCH /v2/time_entries/{id}/restart", s.handleRestartEntry)
+
+	s.Server = httptest.NewServer(s.authenticate(mux))
+	return s
+}
+
+// BaseURL returns the API root to pass to api.ClientOptions
+func (s *Server) BaseURL() string {
+	return s.URL + "/v2/"
+}
+
+// SetCompany replaces the account settings
+func (s *Server) SetCompany(company api.Company) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.company = company
+}
+
+// SetUser replaces the authenticated user
+func (s *Server) SetUser(user api.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = user
+}
+
+// SetToken replaces the token requests must carry, e.g. when an OAuth2
+// access token is issued
+func (s *Server) SetToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Token = token
+}
+
+// AddProject assigns the user to a new project with the given tasks.
+// Zero ids are replaced with generated ones.
+func (s *Server) AddProject(client api.ClientData, project api.Project, tasks ...api.Task) *api.ProjectAssignment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	if client.ID == 0 {
+		client.ID = s.newID()
+	}
+	if project.ID == 0 {
+		project.ID = s.newID()
+	}
+
+	assignment := &api.ProjectAssignment{
+		ID:        s.newID(),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Project:   project,
+		Client:    client,
+	}
+	for _, task := range tasks {
+		if task.ID == 0 {
+			task.ID = s.newID()
+		}
+		assignment.TaskAssignments = append(assignment.TaskAssignments, &api.TaskAssignment{
+			ID:        s.newID(),
+			Billable:  true,
+			IsActive:  true,
+			CreatedAt: now,
+			UpdatedAt: now,
+			Task:      task,
+		})
+	}
+
+	s.projectAssignments = append(s.projectAssignments, assignment)
+	return assignment
+}
+
+// ModifyProject applies fn to a stored project assignment, e.g. to
+// deactivate it. fn sets UpdatedAt for updated_since to see the change. It
+// reports whether the assignment exists.
+func (s *Server) ModifyProject(id int64, fn func(*api.ProjectAssignment)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, assignment := range s.projectAssignments {
+		if assignment.ID == id {
+			fn(assignment)
+			return true
+		}
+	}
+	return false
+}
+
+// Entries returns a snapshot of the stored time entries, newest first
+func (s *Server) Entries() []api.TimeEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]api.TimeEntry, 0, len(s.entries))
+	for _, entry := range s.sortedEntries() {
+		entries = append(entries, s.snapshot(entry))
+	}
+	return entries
+}
+
+// ModifyEntry applies fn to a stored time entry, e.g. to lock or bill it.
+// It reports whether the entry exists.
+func (s *Server) ModifyEntry(id int64, fn func(*api.TimeEntry)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return false
+	}
+	fn(entry)
+	return true
+}
+
+// FailNext makes the next request fail with the given status. A positive
+// retryAfter is sent in the Retry-After header. Calls queue up.
+func (s *Server) FailNext(status int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure{status: status, retryAfter: retryAfter})
+}
+
+func (s *Server) newID() int64 {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+func (s *Server) sortedEntries() []*api.TimeEntry {
+	entries := make([]*api.TimeEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].SpentDate != entries[j].SpentDate {
+			return entries[i].SpentDate > entries[j].SpentDate
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	return entries
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		token := s.Token
+		s.mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			writeError(w, http.StatusUnauthorized, "invalid_token",
+				"The access token provided is expired, revoked, malformed or invalid for other reasons.")
+			return
+		}
+		if r.Header.Get("Harvest-Account-Id") != s.AccountID {
+			writeError(w, http.StatusForbidden, "forbidden", "The account id does not match the access token.")
+			return
+		}
+
+		s.mu.Lock()
+		var injected *failure
+		if len(s.failures) > 0 {
+			injected = &s.failures[0]
+			s.failures = s.failures[1:]
+		}
+		s.mu.Unlock()
+
+		if injected != nil {
+			if injected.retryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(injected.retryAfter.Seconds())))
+			}
+			writeMessage(w, injected.status, http.StatusText(injected.status))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleGetCompany(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.company)
+}
+
+func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.user)
+}
+
+func (s *Server) handleListProjectAssignments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assignments := s.projectAssignments
+	if v := r.URL.Query().Get("updated_since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeMessage(w, http.StatusUnprocessableEntity, "updated_since must be an ISO 8601 date time")
+			return
+		}
+		assignments = nil
+		for _, assignment := range s.projectAssignments {
+			if assignment.UpdatedAt.After(since) {
+				assignments = append(assignments, assignment)
+			}
+		}
+	}
+
+	items, pagination, err := paginate(r, assignments, s.PerPage)
+	if err != nil {
+		writeMessage(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, api.ListAssignedProjectsResponse{
+		ProjectAssignments: items,
+		Pagination:         pagination,
+	})
+}
+
+func (s *Server) handleListEntries(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := filterEntries(r.URL.Query(), s.sortedEntries())
+	if err != nil {
+		writeMessage(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	items, pagination, err := paginate(r, entries, s.PerPage)
+	if err != nil {
+		writeMessage(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	snapshots := make([]*api.TimeEntry, len(items))
+	for i, entry := range items {
+		snapshot := s.snapshot(entry)
+		snapshots[i] = &snapshot
+	}
+	writeJSON(w, http.StatusOK, api.ListEntriesResponse{
+		TimeEntries: snapshots,
+		Pagination:  pagination,
+	})
+}
+
+// entryRequest is the union of the fields accepted when creating or
+// updating a time entry
+type entryRequest struct {
+	ProjectId         *int64                 `json:"project_id"`
+	TaskId            *int64                 `json:"task_id"`
+	SpentDate         *string                `json:"spent_date"`
+	Hours             *float64               `json:"hours"`
+	Notes             *string                `json:"notes"`
+	StartedTime       *string                `json:"started_time"`
+	EndedTime         *string                `json:"ended_time"`
+	ExternalReference *api.ExternalReference `json:"external_reference"`
+}
+
+func (s *Server) handleCreateEntry(w http.ResponseWriter, r *http.Request) {
+	var req entryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMessage(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if req.ProjectId == nil {
+		writeMessage(w, http.StatusUnprocessableEntity, "Project can't be blank")
+		return
+	}
+	if req.TaskId == nil {
+		writeMessage(w, http.StatusUnprocessableEntity, "Task can't be blank")
+		return
+	}
+	if req.SpentDate == nil {
+		writeMessage(w, http.StatusUnprocessableEntity, "Spent date can't be blank")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	entry := &api.TimeEntry{
+		ID:             s.newID(),
+		User:           s.user,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		ApprovalStatus: "unsubmitted",
+		Billable:       true,
+	}
+	if msg := s.applyEntryRequest(entry, req); msg != "" {
+		writeMessage(w, http.StatusUnprocessableEntity, msg)
+		return
+	}
+	if req.Hours == nil && req.EndedTime == nil {
+		// Harvest starts a timer when no duration is given
+		s.startTimer(entry, now)
+	}
+
+	s.entries[entry.ID] = entry
+	writeJSON(w, http.StatusCreated, s.snapshot(entry))
+}
+
+func (s *Server) handleGetEntry(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookupEntry(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.snapshot(entry))
+}
+
+func (s *Server) handleUpdateEntry(w http.ResponseWriter, r *http.Request) {
+	var req entryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMessage(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookupEntry(w, r)
+	if !ok {
+		return
+	}
+	if entry.IsLocked {
+		writeMessage(w, http.StatusForbidden, "Time entry is locked")
+		return
+	}
+
+	updated := *entry
+	if msg := s.applyEntryRequest(&updated, req); msg != "" {
+		writeMessage(w, http.StatusUnprocessableEntity, msg)
+		return
+	}
+	updated.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+
+	*entry = updated
+	writeJSON(w, http.StatusOK, s.snapshot(entry))
+}
+
+func (s *Server) handleDeleteEntry(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookupEntry(w, r)
+	if !ok {
+		return
+	}
+	if entry.IsLocked {
+		writeMessage(w, http.StatusForbidden, "Time entry is locked")
+		return
+	}
+
+	delete(s.entries, entry.ID)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleStopEntry(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookupEntry(w, r)
+	if !ok {
+		return
+	}
+	if !entry.IsRunning {
+		writeMessage(w, http.StatusUnprocessableEntity, "Time entry is not running")
+		return
+	}
+
+	s.stopTimer(entry, time.Now().UTC())
+	writeJSON(w, http.StatusOK, s.snapshot(entry))
+}
+
+func (s *Server) handleRestartEntry(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.lookupEntry(w, r)
+	if !ok {
+		return
+	}
+	if entry.IsRunning {
+		writeMessage(w, http.StatusUnprocessableEntity, "Time entry is already running")
+		return
+	}
+	if entry.IsLocked {
+		writeMessage(w, http.StatusForbidden, "Time entry is locked")
+		return
+	}
+
+	s.startTimer(entry, time.Now().UTC())
+	writeJSON(w, http.StatusOK, s.snapshot(entry))
+}
+
+// startTimer marks entry as running, stopping the user's other timer like
+// Harvest does
+func (s *Server) startTimer(entry *api.TimeEntry, now time.Time) {
+	for _, other := range s.entries {
+		if other.IsRunning && other.ID != entry.ID {
+			s.stopTimer(other, now)
+		}
+	}
+
+	now = now.Truncate(time.Second)
+	entry.IsRunning = true
+	entry.TimerStartedAt = &now
+	entry.UpdatedAt = now
+}
+
+// stopTimer adds the time elapsed since the timer started to the entry
+func (s *Server) stopTimer(entry *api.TimeEntry, now time.Time) {
+	if entry.TimerStartedAt != nil {
+		entry.HoursWithoutTimer += now.Sub(*entry.TimerStartedAt).Hours()
+	}
+	entry.Hours = entry.HoursWithoutTimer
+	entry.RoundedHours = entry.Hours
+	entry.IsRunning = false
+	entry.TimerStartedAt = nil
+	entry.UpdatedAt = now.Truncate(time.Second)
+}
+
+// snapshot returns a copy of entry with the hours of a running timer
+// brought up to date
+func (s *Server) snapshot(entry *api.TimeEntry) api.TimeEntry {
+	snapshot := *entry
+	if entry.IsRunning && entry.TimerStartedAt != nil {
+		// Harvest reports hours with two decimals
+		elapsed := entry.HoursWithoutTimer + time.Since(*entry.TimerStartedAt).Hours()
+		snapshot.Hours = math.Round(elapsed*100) / 100
+		snapshot.RoundedHours = snapshot.Hours
+	}
+	return snapshot
+}
+
+func (s *Server) lookupEntry(w http.ResponseWriter, r *http.Request) (*api.TimeEntry, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not_found", "Time entry not found")
+		return nil, false
+	}
+	entry, ok := s.entries[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "Time entry not found")
+		return nil, false
+	}
+	return entry, true
+}
+
+// applyEntryRequest copies the set fields of req onto entry, resolving
+// project and task ids against the user's assignments. It returns a
+// validation message when the request is rejected.
+func (s *Server) applyEntryRequest(entry *api.TimeEntry, req entryRequest) string {
+	projectId := entry.Project.ID
+	if req.ProjectId != nil {
+		projectId = *req.ProjectId
+	}
+	taskId := entry.Task.ID
+	if req.TaskId != nil {
+		taskId = *req.TaskId
+	}
+
+	var assignment *api.ProjectAssignment
+	for _, candidate := range s.projectAssignments {
+		if candidate.Project.ID == projectId {
+			assignment = candidate
+			break
+		}
+	}
+	if assignment == nil {
+		return "Project is not assigned to the user"
+	}
+
+	var taskAssignment *api.TaskAssignment
+	for _, candidate := range assignment.TaskAssignments {
+		if candidate.Task.ID == taskId {
+			taskAssignment = candidate
+			break
+		}
+	}
+	if taskAssignment == nil {
+		return "Task is not assigned to the project"
+	}
+
+	if req.SpentDate != nil {
+		if _, err := time.Parse("2006-01-02", *req.SpentDate); err != nil {
+			return "Spent date is not a valid date"
+		}
+		entry.SpentDate = *req.SpentDate
+	}
+	if req.Hours != nil {
+		if *req.Hours < 0 || *req.Hours > 24 {
+			return "Hours must be between 0 and 24"
+		}
+		entry.Hours = *req.Hours
+		entry.RoundedHours = *req.Hours
+	}
+	if req.Notes != nil {
+		if len([]rune(*req.Notes)) > api.MaxNotesLength {
+			return fmt.Sprintf("Notes is too long (maximum is %d characters)", api.MaxNotesLength)
+		}
+		entry.Notes = req.Notes
+	}
+	if req.StartedTime != nil {
+		entry.StartedTime = req.StartedTime
+	}
+	if req.EndedTime != nil {
+		entry.EndedTime = req.EndedTime
+	}
+	if req.Hours == nil && entry.StartedTime != nil && entry.EndedTime != nil {
+		started, err := time.Parse(clockLayout, *entry.StartedTime)
+		if err != nil {
+			return "Started time is not a valid time"
+		}
+		ended, err := time.Parse(clockLayout, *entry.EndedTime)
+		if err != nil {
+			return "Ended time is not a valid time"
+		}
+		if ended.Before(started) {
+			return "Ended time must be after started time"
+		}
+		entry.Hours = ended.Sub(started).Hours()
+		entry.RoundedHours = entry.Hours
+	}
+	if req.ExternalReference != nil {
+		entry.ExternalReference = req.ExternalReference
+	}
+
+	entry.Client = assignment.Client
+	entry.Project = assignment.Project
+	entry.Task = taskAssignment.Task
+	entry.TaskAssignment = *taskAssignment
+	entry.UserAssignment = api.UserAssignment{
+		ID:       assignment.ID,
+		IsActive: assignment.IsActive,
+	}
+	entry.Billable = taskAssignment.Billable
+	entry.HoursWithoutTimer = entry.Hours
+	return ""
+}
+
+// filterEntries keeps the entries matching the time entry query parameters
+func filterEntries(query url.Values, entries []*api.TimeEntry) ([]*api.TimeEntry, error) {
+	var matchers []func(*api.TimeEntry) bool
+
+	for _, key := range []string{"from", "to"} {
+		v := query.Get(key)
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			return nil, fmt.Errorf("%s must be a date formatted as YYYY-MM-DD", key)
+		}
+		if key == "from" {
+			matchers = append(matchers, func(e *api.TimeEntry) bool { return e.SpentDate >= v })
+		} else {
+			matchers = append(matchers, func(e *api.TimeEntry) bool { return e.SpentDate <= v })
+		}
+	}
+
+	ids := map[string]func(*api.TimeEntry) int64{
+		"user_id":    func(e *api.TimeEntry) int64 { return e.User.ID },
+		"client_id":  func(e *api.TimeEntry) int64 { return e.Client.ID },
+		"project_id": func(e *api.TimeEntry) int64 { return e.Project.ID },
+		"task_id":    func(e *api.TimeEntry) int64 { return e.Task.ID },
+	}
+	for key, field := range ids {
+		v := query.Get(key)
+		if v == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer", key)
+		}
+		matchers = append(matchers, func(e *api.TimeEntry) bool { return field(e) == id })
+	}
+
+	flags := map[string]func(*api.TimeEntry) bool{
+		"is_billed":  func(e *api.TimeEntry) bool { return e.IsBilled },
+		"is_running": func(e *api.TimeEntry) bool { return e.IsRunning },
+	}
+	for key, field := range flags {
+		v := query.Get(key)
+		if v == "" {
+			continue
+		}
+		want, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", key)
+		}
+		matchers = append(matchers, func(e *api.TimeEntry) bool { return field(e) == want })
+	}
+
+	if v := query.Get("external_reference_id"); v != "" {
+		matchers = append(matchers, func(e *api.TimeEntry) bool {
+			return e.ExternalReference != nil && e.ExternalReference.ID == v
+		})
+	}
+	if v := query.Get("approval_status"); v != "" {
+		switch v {
+		case api.ApprovalUnsubmitted, api.ApprovalSubmitted, api.ApprovalApproved:
+		default:
+			return nil, fmt.Errorf("approval_status must be unsubmitted, submitted or approved")
+		}
+		matchers = append(matchers, func(e *api.TimeEntry) bool { return e.ApprovalStatus == v })
+	}
+	if v := query.Get("updated_since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("updated_since must be an ISO 8601 date time")
+		}
+		matchers = append(matchers, func(e *api.TimeEntry) bool { return e.UpdatedAt.After(since) })
+	}
+
+	var filtered []*api.TimeEntry
+	for _, entry := range entries {
+		keep := true
+		for _, match := range matchers {
+			if !match(entry) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered, nil
+}
+
+// paginate slices items according to the page and per_page query parameters
+// and builds the matching pagination metadata
+func paginate[T any](r *http.Request, items []T, defaultPerPage int) ([]T, api.Pagination, error) {
+	query := r.URL.Query()
+
+	page := 1
+	if v := query.Get("page"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 1 {
+			return nil, api.Pagination{}, fmt.Errorf("page must be a positive integer")
+		}
+		page = p
+	}
+	perPage := defaultPerPage
+	if v := query.Get("per_page"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 1 || p > 2000 {
+			return nil, api.Pagination{}, fmt.Errorf("per_page must be between 1 and 2000")
+		}
+		perPage = p
+	}
+
+	totalPages := (len(items) + perPage - 1) / perPage
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+
+	pagination := api.Pagination{
+		PerPage:      perPage,
+		TotalPages:   totalPages,
+		TotalEntries: len(items),
+		Page:         page,
+		Links: api.Links{
+			First: pageURL(r, 1, perPage),
+			Last:  pageURL(r, totalPages, perPage),
+		},
+	}
+	if page < totalPages {
+		next := page + 1
+		link := pageURL(r, next, perPage)
+		pagination.NextPage = &next
+		pagination.Links.Next = &link
+	}
+	if page > 1 {
+		previous := page - 1
+		link := pageURL(r, previous, perPage)
+		pagination.PreviousPage = &previous
+		pagination.Links.Previous = &link
+	}
+
+	return items[start:end], pagination, nil
+}
+
+func pageURL(r *http.Request, page, perPage int) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	u := url.URL{
+		Scheme:   "http",
+		Host:     r.Host,
+		Path:     r.URL.Path,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
@@ -2,44 +2,117 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 )
 
-func (c *Client) makeRequest(method, endpoint string, body interface{}, result interface{}) error {
-	url := "https://api.harvestapp.com/v2/" + endpoint
+func (c *Client) makeRequest(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+	url := endpoint
+	if !strings.HasPrefix(endpoint, "https://") && !strings.HasPrefix(endpoint, "http://") {
+		// Pagination links are already absolute
+		url = c.baseURL + strings.TrimPrefix(endpoint, "/")
+	}
 
-	var bodyReader io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request body: %w", err)
 		}
+	}
+
+	if c.dryRun != nil && isMutating(method) {
+		return c.simulate(ctx, method, url, jsonBody, result)
+	}
+
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		canRetry := attempt < c.retry.MaxRetries
+
+		token, err := c.currentToken(ctx)
+		if err != nil {
+			return err
+		}
+		resp, err := c.send(ctx, method, url, token, jsonBody)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("request cancelled: %w", ctx.Err())
+			}
+			if canRetry && isIdempotent(method) {
+				if err := sleep(ctx, c.retry.backoff(attempt)); err != nil {
+					return fmt.Errorf("request cancelled: %w", err)
+				}
+				continue
+			}
+			return fmt.Errorf("request failed: %w", err)
+		}
+
+		// A rejected token is refreshed once, the request was not processed
+		// so it is resent whatever the method
+		if resp.StatusCode == http.StatusUnauthorized && c.refreshToken != nil && !refreshed {
+			refreshed = true
+			rejected := newError(resp)
+			resp.Body.Close()
+			if err := c.refresh(ctx, token); err != nil {
+				// Report the rejection, which tells the user to log in again
+				return fmt.Errorf("%v: %w", err, rejected)
+			}
+			continue
+		}
+
+		// 429 means Harvest rejected the request before processing it, but
+		// a 5xx may have been applied so only idempotent methods are resent
+		if canRetry && shouldRetry(resp.StatusCode, method) {
+			delay, ok := retryAfter(resp)
+			if !ok {
+				delay = c.retry.backoff(attempt)
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if err := sleep(ctx, delay); err != nil {
+				return fmt.Errorf("request cancelled: %w", err)
+			}
+			continue
+		}
+
+		return decodeResponse(resp, result)
+	}
+}
+
+// send performs a single HTTP request, waiting for the rate limiter first
+func (c *Client) send(ctx context.Context, method, url, token string, jsonBody []byte) (*http.Response, error) {
+	var bodyReader io.Reader
+	if jsonBody != nil {
 		bodyReader = bytes.NewReader(jsonBody)
 	}
 
-	req, err := http.NewRequest(method, url, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Harvest-Account-Id", c.accountId)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "haverst-cli/1.0")
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, err
 	}
+	return c.httpClient.Do(req)
+}
+
+func decodeResponse(resp *http.Response, result interface{}) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return newError(resp)
 	}
 
 	if result == nil {
@@ -52,3 +125,22 @@ func (c *Client) makeRequest(method, endpoint string, body interface{}, result i
 
 	return nil
 }
+
+// newError builds an *Error from a failed response
+func newError(resp *http.Response) *Error {
+	bodyBytes, _ := io.ReadAll(resp.Body)
+
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Method:     resp.Request.Method,
+		Path:       resp.Request.URL.Path,
+	}
+	if err := json.Unmarshal(bodyBytes, apiErr); err != nil {
+		apiErr.Body = strings.TrimSpace(string(bodyBytes))
+	}
+	if delay, ok := retryAfter(resp); ok {
+		apiErr.RetryAfter = delay
+	}
+
+	return apiErr
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultIdentityURL is the root of Harvest ID, Harvest's OAuth2 server
+const DefaultIdentityURL = "https://id.getharvest.com/"
+
+// OAuthOptions configures the OAuth2 client
+type OAuthOptions struct {
+	// IdentityURL overrides the Harvest ID root, e.g. to point at a fake server
+	IdentityURL string
+	// RedirectPort is the loopback port receiving the authorization code,
+	// which must match the redirect URL of the OAuth2 application. Zero
+	// picks a free port.
+	RedirectPort int
+	// Timeout bounds each HTTP request, including reading the response
+	Timeout time.Duration
+}
+
+// OAuth signs users in with the OAuth2 authorization code flow of an
+// application registered at https://id.getharvest.com/developers
+type OAuth struct {
+	clientId     string
+	clientSecret string
+	identityURL  string
+	redirectPort int
+	httpClient   *http.Client
+}
+
+// OAuthToken is a token issued by Harvest ID
+type OAuthToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is the lifetime of the access token in seconds
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// ExpiresAt returns when the access token expires, counting from now
+func (t *OAuthToken) ExpiresAt() time.Time {
+	return time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+}
+
+// Account is a Harvest or Forecast account the user can access
+type Account struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Product string `json:"product"`
+}
+
+// AccountsResponse is the answer of the accounts endpoint of Harvest ID
+type AccountsResponse struct {
+	User     User      `json:"user"`
+	Accounts []Account `json:"accounts"`
+}
+
+func NewOAuth(clientId, clientSecret string, options OAuthOptions) (*OAuth, error) {
+	if clientId == "" {
+		return nil, fmt.Errorf("OAuth2 client id is required")
+	}
+	if clientSecret == "" {
+		return nil, fmt.Errorf("OAuth2 client secret is required")
+	}
+
+	// Set defaults
+	if options.IdentityURL == "" {
+		options.IdentityURL = DefaultIdentityURL
+	}
+	if !strings.HasSuffix(options.IdentityURL, "/") {
+		options.IdentityURL += "/"
+	}
+	if options.Timeout == 0 {
+		options.Timeout = 30 * time.Second
+	}
+
+	return &OAuth{
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		identityURL:  options.IdentityURL,
+		redirectPort: options.RedirectPort,
+		httpClient: &http.Client{
+			Timeout: options.Timeout,
+		},
+	}, nil
+}
+
+// AuthorizeURL returns the page where the user grants the application
+// access, redirecting to redirectURI with the code and state
+func (o *OAuth) AuthorizeURL(redirectURI, state string) string {
+	query := url.Values{}
+	query.Set("client_id", o.clientId)
+	query.Set("response_type", "code")
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	return o.identityURL + "oauth2/authorize?" + query.Encode()
+}
+
+// Authorize runs the authorization code flow. It listens on a loopback
+// address, calls open with the page the user has to visit, waits for the
+// redirect of this login until ctx ends and exchanges the code it carries
+// for a token.
+func (o *OAuth) Authorize(ctx context.Context, open func(authorizeURL string) error) (*OAuthToken, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(o.redirectPort)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the redirect: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr())
+
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+
+	type callback struct {
+		code string
+		err  error
+	}
+	callbacks := make(chan callback, 1)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/callback" {
+				http.NotFound(w, r)
+				return
+			}
+
+			// Any page can send the browser here, so redirects of another
+			// login are turned down and the real one is still awaited
+			query := r.URL.Query()
+			if query.Get("state") != state {
+				http.Error(w, "The redirect does not belong to this login.", http.StatusBadRequest)
+				return
+			}
+
+			var result callback
+			switch {
+			case query.Get("error") != "":
+				result.err = fmt.Errorf("authorization denied: %s", query.Get("error"))
+			case query.Get("code") == "":
+				result.err = fmt.Errorf("the redirect carries no authorization code")
+			default:
+				result.code = query.Get("code")
+			}
+
+			if result.err != nil {
+				http.Error(w, result.err.Error(), http.StatusBadRequest)
+			} else {
+				fmt.Fprintln(w, "You are logged in, you can close this window and return to the terminal.")
+			}
+
+			// Only the first redirect counts
+			select {
+			case callbacks <- result:
+			default:
+			}
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if err := open(o.AuthorizeURL(redirectURI, state)); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("login cancelled: %w", ctx.Err())
+	case result := <-callbacks:
+		if result.err != nil {
+			return nil, result.err
+		}
+		return o.Exchange(ctx, result.code, redirectURI)
+	}
+}
+
+// Exchange trades an authorization code for a token
+func (o *OAuth) Exchange(ctx context.Context, code, redirectURI string) (*OAuthToken, error) {
+	return o.requestToken(ctx, url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {redirectURI},
+	})
+}
+
+// Refresh trades a refresh token for a new token
+func (o *OAuth) Refresh(ctx context.Context, refreshToken string) (*OAuthToken, error) {
+	return o.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+// Accounts returns the user the access token belongs to and the accounts
+// they can access
+func (o *OAuth) Accounts(ctx context.Context, accessToken string) (*AccountsResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.identityURL+"api/v2/accounts", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	var accounts AccountsResponse
+	if err := o.do(req, &accounts); err != nil {
+		return nil, err
+	}
+	return &accounts, nil
+}
+
+// HarvestAccounts returns the accounts of the Harvest product, leaving out
+// Forecast ones
+func (r *AccountsResponse) HarvestAccounts() []Account {
+	var accounts []Account
+	for _, account := range r.Accounts {
+		if account.Product == "harvest" {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts
+}
+
+func (o *OAuth) requestToken(ctx context.Context, form url.Values) (*OAuthToken, error) {
+	form.Set("client_id", o.clientId)
+	form.Set("client_secret", o.clientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.identityURL+"api/v2/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token OAuthToken
+	if err := o.do(req, &token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("Harvest ID issued no access token")
+	}
+	return &token, nil
+}
+
+func (o *OAuth) do(req *http.Request, result interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "haverst-cli/1.0")
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		if req.Context().Err() != nil {
+			return fmt.Errorf("request cancelled: %w", req.Context().Err())
+		}
+		return fmt.Errorf("request failed: %w", err)
+	}
+	return decodeResponse(resp, result)
+}
+
+// randomState returns the value tying the redirect to this login
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,122 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/api/fake"
+)
+
+// redirect sends the browser to the redirect URL of the authorize page,
+// with the given query instead of the one Harvest ID would set. It returns
+// the status of the response, 0 when the listener is already closed.
+func redirect(page string, query url.Values) int {
+	authorize, _ := url.Parse(page)
+	resp, err := http.Get(authorize.Query().Get("redirect_uri") + "?" + query.Encode())
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// stateOf returns the state of the authorize page
+func stateOf(page string) string {
+	authorize, _ := url.Parse(page)
+	return authorize.Query().Get("state")
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name string
+		// browse does what the browser would with the authorize page
+		browse func(t *testing.T, page string)
+		// wantErr is part of the error returned, empty for a token
+		wantErr string
+	}{
+		{
+			name: "redirect of this login",
+			browse: func(t *testing.T, page string) {
+				http.Get(page)
+			},
+		},
+		{
+			name: "redirect of another login first",
+			browse: func(t *testing.T, page string) {
+				if status := redirect(page, url.Values{"code": {"forged"}, "state": {"forged"}}); status != http.StatusBadRequest {
+					t.Errorf("got status %d for a redirect of another login, want 400", status)
+				}
+				if status := redirect(page, url.Values{"error": {"access_denied"}}); status != http.StatusBadRequest {
+					t.Errorf("got status %d for a redirect without state, want 400", status)
+				}
+				http.Get(page)
+			},
+		},
+		{
+			name: "denied",
+			browse: func(t *testing.T, page string) {
+				redirect(page, url.Values{"error": {"access_denied"}, "state": {stateOf(page)}})
+			},
+			wantErr: "authorization denied: access_denied",
+		},
+		{
+			name: "only redirects of another login",
+			browse: func(t *testing.T, page string) {
+				redirect(page, url.Values{"code": {"forged"}, "state": {"forged"}})
+			},
+			wantErr: "login cancelled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fake.NewServer()
+			defer srv.Close()
+			identity := fake.NewIdentity(srv)
+			defer identity.Close()
+
+			oauth, err := api.NewOAuth(identity.ClientID, identity.ClientSecret, api.OAuthOptions{IdentityURL: identity.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			browsed := make(chan struct{})
+			token, err := oauth.Authorize(ctx, func(page string) error {
+				go func() {
+					defer close(browsed)
+					tt.browse(t, page)
+				}()
+				return nil
+			})
+			<-browsed
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Authorize returned %v, want an error with %q", err, tt.wantErr)
+				}
+				if tt.wantErr == "login cancelled" && !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("Authorize returned %v, want it to wait until the context ends", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authorize: %v", err)
+			}
+
+			client, err := api.NewClient(token.AccessToken, srv.AccountID, api.ClientOptions{BaseURL: srv.BaseURL()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.GetMe(ctx); err != nil {
+				t.Errorf("the issued token was rejected: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Pagination holds the paging metadata Harvest attaches to every list response
+type Pagination struct {
+	PerPage      int   `json:"per_page"`
+	TotalPages   int   `json:"total_pages"`
+	TotalEntries int   `json:"total_entries"`
+	NextPage     *int  `json:"next_page"`
+	PreviousPage *int  `json:"previous_page"`
+	Page         int   `json:"page"`
+	Links        Links `json:"links"`
+}
+
+func (p *Pagination) pagination() *Pagination {
+	return p
+}
+
+// nextEndpoint returns the endpoint of the page following this one, or an
+// empty string when this is the last page. links.next is preferred because
+// it carries every query parameter of the original request.
+func (p *Pagination) nextEndpoint(current string) (string, error) {
+	if p.Links.Next != nil && *p.Links.Next != "" {
+		return *p.Links.Next, nil
+	}
+	if p.NextPage == nil {
+		return "", nil
+	}
+
+	u, err := url.Parse(current)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse endpoint %q: %w", current, err)
+	}
+	query := u.Query()
+	query.Set("page", strconv.Itoa(*p.NextPage))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// page is implemented by the list responses that can be walked with paginate
+type page[T any] interface {
+	items() []T
+	pagination() *Pagination
+}
+
+// paginate returns an iterator over every item of a list endpoint, fetching
+// the following pages on demand until Harvest reports there are none left.
+// newPage must return a fresh response value for each request.
+func paginate[T any](ctx context.Context, c *Client, endpoint string, newPage func() page[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		next := endpoint
+		for next != "" {
+			response := newPage()
+			if err := c.makeRequest(ctx, "GET", next, nil, response); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range response.items() {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			var err error
+			next, err = response.pagination().nextEndpoint(next)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+		}
+	}
+}
+
+// collect drains an iterator returned by paginate into a slice
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var all []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, item)
+	}
+	return all, nil
+}
+
+// withQuery appends the encoded query parameters to endpoint, if any
+func withQuery(endpoint string, queryParams url.Values) string {
+	if len(queryParams) > 0 {
+		endpoint += "?" + queryParams.Encode()
+	}
+	return endpoint
+}
+
+// values encodes the list parameters as query parameters
+func (p ListParams) values() url.Values {
+	queryParams := url.Values{}
+	if p.UpdatedSince != nil {
+		queryParams.Set("updated_since", p.UpdatedSince.UTC().Format(time.RFC3339))
+	}
+	if p.Page > 0 {
+		queryParams.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PerPage > 0 {
+		queryParams.Set("per_page", strconv.Itoa(p.PerPage))
+	}
+	return queryParams
+}
+
+// values encodes the query as query parameters
+func (q TimeEntryQuery) values() url.Values {
+	queryParams := q.ListParams.values()
+	if q.From != "" {
+		queryParams.Set("from", q.From)
+	}
+	if q.To != "" {
+		queryParams.Set("to", q.To)
+	}
+	setID := func(key string, id int64) {
+		if id != 0 {
+			queryParams.Set(key, strconv.FormatInt(id, 10))
+		}
+	}
+	setID("user_id", q.UserId)
+	setID("client_id", q.ClientId)
+	setID("project_id", q.ProjectId)
+	setID("task_id", q.TaskId)
+	if q.IsBilled != nil {
+		queryParams.Set("is_billed", strconv.FormatBool(*q.IsBilled))
+	}
+	if q.IsRunning != nil {
+		queryParams.Set("is_running", strconv.FormatBool(*q.IsRunning))
+	}
+	if q.ExternalReferenceId != "" {
+		queryParams.Set("external_reference_id", q.ExternalReferenceId)
+	}
+	if q.ApprovalStatus != "" {
+		queryParams.Set("approval_status", q.ApprovalStatus)
+	}
+	return queryParams
+}
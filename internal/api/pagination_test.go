@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestNextEndpoint(t *testing.T) {
+	page := func(n int) *int { return &n }
+	link := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		current    string
+		pagination Pagination
+		want       string
+	}{
+		{
+			name:       "links.next",
+			current:    "time_entries?from=2024-05-01",
+			pagination: Pagination{NextPage: page(2), Links: Links{Next: link("https://api.harvestapp.com/v2/time_entries?from=2024-05-01&page=2")}},
+			want:       "https://api.harvestapp.com/v2/time_entries?from=2024-05-01&page=2",
+		},
+		{
+			name:       "next_page keeping the query",
+			current:    "time_entries?from=2024-05-01&per_page=2",
+			pagination: Pagination{NextPage: page(2)},
+			want:       "time_entries?from=2024-05-01&page=2&per_page=2",
+		},
+		{
+			name:       "next_page replacing the page",
+			current:    "time_entries?page=2",
+			pagination: Pagination{NextPage: page(3), Links: Links{Next: link("")}},
+			want:       "time_entries?page=3",
+		},
+		{
+			name:       "last page",
+			current:    "time_entries?page=3",
+			pagination: Pagination{PreviousPage: page(2), Links: Links{Previous: link("time_entries?page=2")}},
+			want:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.pagination.nextEndpoint(tt.current)
+			if err != nil {
+				t.Fatalf("nextEndpoint(%q): %v", tt.current, err)
+			}
+			if got != tt.want {
+				t.Errorf("nextEndpoint(%q) = %q, want %q", tt.current, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	const pages = 3
+
+	tests := []struct {
+		name string
+		// links sets links.next, otherwise only next_page is set
+		links bool
+	}{
+		{name: "links.next", links: true},
+		{name: "next_page", links: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			var srv *httptest.Server
+			srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.URL.RequestURI())
+				if r.URL.Query().Get("from") != "2024-05-01" {
+					t.Errorf("got %s, want the query of the first request", r.URL.RequestURI())
+				}
+
+				current := 1
+				if value := r.URL.Query().Get("page"); value != "" {
+					current, _ = strconv.Atoi(value)
+				}
+				response := ListEntriesResponse{
+					TimeEntries: []*TimeEntry{{ID: int64(current)}},
+					Pagination:  Pagination{Page: current, TotalPages: pages},
+				}
+				if current < pages {
+					next := current + 1
+					response.NextPage = &next
+					if tt.links {
+						link := fmt.Sprintf("%s/v2/time_entries?from=2024-05-01&page=%d", srv.URL, next)
+						response.Links.Next = &link
+					}
+				}
+				json.NewEncoder(w).Encode(response)
+			}))
+			defer srv.Close()
+
+			client, err := NewClient("token", "1", ClientOptions{BaseURL: srv.URL + "/v2/"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries, err := client.ListEntries(context.Background(), TimeEntryQuery{From: "2024-05-01"})
+			if err != nil {
+				t.Fatalf("ListEntries: %v", err)
+			}
+
+			if len(entries) != pages {
+				t.Fatalf("ListEntries returned %d entries, want one per page, %d", len(entries), pages)
+			}
+			for i, entry := range entries {
+				if entry.ID != int64(i+1) {
+					t.Errorf("entry %d is the one of page %d, want page %d", i, entry.ID, i+1)
+				}
+			}
+			if len(requests) != pages {
+				t.Errorf("got requests %v, want one per page", requests)
+			}
+		})
+	}
+}
@@ -1,32 +1,21 @@
 package api
 
 import (
-	"net/url"
-	"strconv"
+	"context"
+	"iter"
 )
 
-func (c *Client) ListAssignedProjects(params ListParams) ([]*ProjectAssignment, error) {
-	queryParams := url.Values{}
-	if params.Limit > 0 {
-		queryParams.Set("limit", strconv.Itoa(params.Limit))
-	}
-	if params.Offset > 0 {
-		queryParams.Set("offset", strconv.Itoa(params.Offset))
-	}
-	if params.Filter != "" {
-		queryParams.Set("filter", params.Filter)
-	}
+// AssignedProjects returns an iterator over every project assignment of the
+// current user, following Harvest's pagination as it goes
+func (c *Client) AssignedProjects(ctx context.Context, params ListParams) iter.Seq2[*ProjectAssignment, error] {
+	endpoint := withQuery("/users/me/project_assignments", params.values())
 
-	endpoint := "/users/me/project_assignments"
-	if len(queryParams) > 0 {
-		endpoint += "?" + queryParams.Encode()
-	}
-
-	var response ListAssignedProjectsResponse
-	err := c.makeRequest("GET", endpoint, nil, &response)
-	if err != nil {
-		return nil, err
-	}
+	return paginate(ctx, c, endpoint, func() page[*ProjectAssignment] {
+		return &ListAssignedProjectsResponse{}
+	})
+}
 
-	return response.ProjectAssignments, nil
+// ListAssignedProjects returns every project assignment of the current user
+func (c *Client) ListAssignedProjects(ctx context.Context, params ListParams) ([]*ProjectAssignment, error) {
+	return collect(c.AssignedProjects(ctx, params))
 }
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Harvest allows 100 requests per 15 seconds for each access token
+const (
+	DefaultRateLimit  = 100
+	DefaultRateWindow = 15 * time.Second
+)
+
+// RetryPolicy controls how failed requests are retried
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	// Zero uses the default, a negative value disables retries.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry, doubled on each attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between two attempts
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is used for the zero fields of ClientOptions.Retry
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 4,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxRetries == 0 {
+		p.MaxRetries = DefaultRetryPolicy.MaxRetries
+	}
+	if p.MaxRetries < 0 {
+		p.MaxRetries = 0
+	}
+	if p.BaseDelay == 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	return p
+}
+
+// backoff returns a jittered exponential delay for the given attempt,
+// between half and all of BaseDelay for the first retry
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if attempt < 30 {
+		delay = min(p.BaseDelay<<attempt, p.MaxDelay)
+	}
+	// Jitter over the upper half keeps concurrent scripts from retrying in
+	// lockstep without shortening the wait too much
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// isIdempotent reports whether a request can be sent again without risking
+// a duplicate side effect
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether a request answered with status can be sent
+// again: a rate limited request was not processed whatever its method, a
+// server error may have been
+func shouldRetry(status int, method string) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	return isRetryableStatus(status) && isIdempotent(method)
+}
+
+// isRetryableStatus reports whether a response status is worth retrying
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryAfter parses the Retry-After header, either as a number of seconds
+// or as an HTTP date. It returns false when the header is absent or invalid.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// rateLimiter keeps the client under a number of requests per sliding window
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	sent   []time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:  limit,
+		window: window,
+	}
+}
+
+// reserve records a request and returns how long to wait before sending it
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	// Forget the requests that left the window
+	cutoff := now.Add(-l.window)
+	kept := l.sent[:0]
+	for _, t := range l.sent {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	l.sent = kept
+
+	sendAt := now
+	if len(l.sent) >= l.limit {
+		sendAt = l.sent[len(l.sent)-l.limit].Add(l.window)
+	}
+	l.sent = append(l.sent, sendAt)
+	return sendAt.Sub(now)
+}
+
+// wait blocks until a request can be sent without exceeding the budget
+func (l *rateLimiter) wait(ctx context.Context) error {
+	return sleep(ctx, l.reserve())
+}
+
+// sleep pauses for d, returning early with the context's error if it is
+// cancelled first
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -1,38 +1,34 @@
 package api
 
 import (
-	"net/url"
-	"strconv"
+	"context"
+	"iter"
 )
 
-func (c *Client) ListTasks(projectId int64, params ListParams) ([]*TaskAssignment, error) {
-	queryParams := url.Values{}
-	if params.Limit > 0 {
-		queryParams.Set("limit", strconv.Itoa(params.Limit))
-	}
-	if params.Offset > 0 {
-		queryParams.Set("offset", strconv.Itoa(params.Offset))
-	}
-	if params.Filter != "" {
-		queryParams.Set("filter", params.Filter)
-	}
-
-	endpoint := "/users/me/project_assignments"
-	if len(queryParams) > 0 {
-		endpoint += "?" + queryParams.Encode()
-	}
-
-	var response ListAssignedProjectsResponse
-	err := c.makeRequest("GET", endpoint, nil, &response)
-	if err != nil {
-		return nil, err
-	}
+// Tasks returns an iterator over the task assignments of the given project.
+// It stops fetching project assignments as soon as the project is found.
+func (c *Client) Tasks(ctx context.Context, projectId int64, params ListParams) iter.Seq2[*TaskAssignment, error] {
+	return func(yield func(*TaskAssignment, error) bool) {
+		for projectAssignment, err := range c.AssignedProjects(ctx, params) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if projectAssignment.Project.ID != projectId {
+				continue
+			}
 
-	for _, projectAssignment := range response.ProjectAssignments {
-		if projectAssignment.Project.ID == projectId {
-			return projectAssignment.TaskAssignments, nil
+			for _, taskAssignment := range projectAssignment.TaskAssignments {
+				if !yield(taskAssignment, nil) {
+					return
+				}
+			}
+			return
 		}
 	}
+}
 
-	return nil, nil
+// ListTasks returns every task assignment of the given project
+func (c *Client) ListTasks(ctx context.Context, projectId int64, params ListParams) ([]*TaskAssignment, error) {
+	return collect(c.Tasks(ctx, projectId, params))
 }
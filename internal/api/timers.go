@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+// StartTimer creates a running time entry. Harvest stops any timer that
+// was already running for the user.
+func (c *Client) StartTimer(ctx context.Context, req CreateEntryRequest) (*TimeEntry, error) {
+	// A time entry created without a duration is a running timer
+	req.Hours = 0
+	return c.CreateEntry(ctx, req)
+}
+
+// StopEntry stops the timer of a running time entry
+func (c *Client) StopEntry(ctx context.Context, id int64) (*TimeEntry, error) {
+	var entry TimeEntry
+	endpoint := fmt.Sprintf("/time_entries/%d/stop", id)
+	err := c.makeRequest(ctx, "PATCH", endpoint, nil, &entry)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// RestartEntry starts the timer of a stopped time entry again
+func (c *Client) RestartEntry(ctx context.Context, id int64) (*TimeEntry, error) {
+	var entry TimeEntry
+	endpoint := fmt.Sprintf("/time_entries/%d/restart", id)
+	err := c.makeRequest(ctx, "PATCH", endpoint, nil, &entry)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// RunningEntry returns the running time entry of the given user, or nil
+// when no timer is running
+func (c *Client) RunningEntry(ctx context.Context, userId int64) (*TimeEntry, error) {
+	running := true
+	query := TimeEntryQuery{
+		UserId:    userId,
+		IsRunning: &running,
+	}
+
+	for entry, err := range c.Entries(ctx, query) {
+		if err != nil {
+			return nil, err
+		}
+		return entry, nil
+	}
+	return nil, nil
+}
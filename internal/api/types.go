@@ -2,45 +2,122 @@ package api
 
 import "time"
 
-type Entry struct {
-	ID        string    `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	Tags      []string  `json:"tags"`
-	Status    string    `json:"status"`
-	Private   bool      `json:"private"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+// TimeEntry is a Harvest time entry, either a logged duration or a running timer
+type TimeEntry struct {
+	ID                int64              `json:"id"`
+	SpentDate         string             `json:"spent_date"`
+	User              User               `json:"user"`
+	Client            ClientData         `json:"client"`
+	Project           Project            `json:"project"`
+	Task              Task               `json:"task"`
+	UserAssignment    UserAssignment     `json:"user_assignment"`
+	TaskAssignment    TaskAssignment     `json:"task_assignment"`
+	ExternalReference *ExternalReference `json:"external_reference"`
+	Invoice           *Invoice           `json:"invoice"`
+	Hours             float64            `json:"hours"`
+	HoursWithoutTimer float64            `json:"hours_without_timer"`
+	RoundedHours      float64            `json:"rounded_hours"`
+	Notes             *string            `json:"notes"`
+	IsLocked          bool               `json:"is_locked"`
+	LockedReason      *string            `json:"locked_reason"`
+	IsClosed          bool               `json:"is_closed"`
+	ApprovalStatus    string             `json:"approval_status"`
+	IsBilled          bool               `json:"is_billed"`
+	TimerStartedAt    *time.Time         `json:"timer_started_at"`
+	StartedTime       *string            `json:"started_time"`
+	EndedTime         *string            `json:"ended_time"`
+	IsRunning         bool               `json:"is_running"`
+	Billable          bool               `json:"billable"`
+	Budgeted          bool               `json:"budgeted"`
+	BillableRate      *float64           `json:"billable_rate"`
+	CostRate          *float64           `json:"cost_rate"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+}
+
+// ExternalReference links a time entry to an item in another service,
+// e.g. a GitHub issue or a Trello card
+type ExternalReference struct {
+	ID        string `json:"id"`
+	GroupID   string `json:"group_id"`
+	AccountID string `json:"account_id,omitempty"`
+	Permalink string `json:"permalink"`
+	Service   string `json:"service,omitempty"`
+}
+
+// Invoice is the invoice a billed time entry belongs to
+type Invoice struct {
+	ID     int64  `json:"id"`
+	Number string `json:"number"`
 }
 
 type CreateEntryRequest struct {
-	ProjectId int64  `json:"project_id"`
-	TaskId    int64  `json:"task_id,omitempty"`
-	Date      string `json:"spent_date,omitempty"`
-	Hours     float64    `json:"hours,omitempty"`
+	ProjectId int64   `json:"project_id"`
+	TaskId    int64   `json:"task_id,omitempty"`
+	Date      string  `json:"spent_date,omitempty"`
+	Hours     float64 `json:"hours,omitempty"`
+	Notes     string  `json:"notes,omitempty"`
 }
 
+// UpdateEntryRequest holds the fields to change on a time entry, nil
+// fields are left untouched
 type UpdateEntryRequest struct {
-	Title   *string `json:"title,omitempty"`
-	Content *string `json:"content,omitempty"`
-	Tags    *string `json:"tags,omitempty"`
-	Status  *string `json:"status,omitempty"`
-	Private *bool   `json:"private,omitempty"`
+	ProjectId         *int64             `json:"project_id,omitempty"`
+	TaskId            *int64             `json:"task_id,omitempty"`
+	SpentDate         *string            `json:"spent_date,omitempty"`
+	Hours             *float64           `json:"hours,omitempty"`
+	Notes             *string            `json:"notes,omitempty"`
+	StartedTime       *string            `json:"started_time,omitempty"`
+	EndedTime         *string            `json:"ended_time,omitempty"`
+	ExternalReference *ExternalReference `json:"external_reference,omitempty"`
 }
 
+// ListParams holds the parameters shared by Harvest list endpoints
 type ListParams struct {
-	Limit  int    `json:"limit,omitempty"`
-	Offset int    `json:"offset,omitempty"`
-	Filter string `json:"filter,omitempty"`
+	// UpdatedSince only returns records updated after this time
+	UpdatedSince *time.Time `json:"updated_since,omitempty"`
+	// Page is the first page to fetch, PerPage its size (max 2000)
+	Page    int `json:"page,omitempty"`
+	PerPage int `json:"per_page,omitempty"`
+}
+
+// Approval statuses of a time entry
+const (
+	ApprovalUnsubmitted = "unsubmitted"
+	ApprovalSubmitted   = "submitted"
+	ApprovalApproved    = "approved"
+)
+
+// TimeEntryQuery filters the time entries returned by ListEntries.
+// Zero fields are not sent.
+type TimeEntryQuery struct {
+	// From and To bound spent_date, inclusive, as YYYY-MM-DD
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	UserId    int64 `json:"user_id,omitempty"`
+	ClientId  int64 `json:"client_id,omitempty"`
+	ProjectId int64 `json:"project_id,omitempty"`
+	TaskId    int64 `json:"task_id,omitempty"`
+
+	IsBilled  *bool `json:"is_billed,omitempty"`
+	IsRunning *bool `json:"is_running,omitempty"`
+
+	ExternalReferenceId string `json:"external_reference_id,omitempty"`
+	// ApprovalStatus is one of the Approval constants
+	ApprovalStatus string `json:"approval_status,omitempty"`
+
+	ListParams
 }
 
 type User struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
 }
 
 type UserAssignment struct {
-	ID               int      `json:"id"`
+	ID               int64    `json:"id"`
 	IsProjectManager bool     `json:"is_project_manager"`
 	IsActive         bool     `json:"is_active"`
 	Budget           *float64 `json:"budget"`
@@ -49,51 +126,22 @@ type UserAssignment struct {
 	HourlyRate       float64  `json:"hourly_rate"`
 }
 
-type CreateEntryResponse struct {
-	ID             int            `json:"id"`
-	SpentDate      string         `json:"spent_date"`
-	User           User           `json:"user"`
-	Client         Client         `json:"client"`
-	Project        Project        `json:"project"`
-	Task           Task           `json:"task"`
-	UserAssignment UserAssignment `json:"user_assignment"`
-	TaskAssignment TaskAssignment `json:"task_assignment"`
-	Hours          float64        `json:"hours"`
-	RoundedHours   float64        `json:"rounded_hours"`
-	Notes          *string        `json:"notes"`
-	CreatedAt      string         `json:"created_at"`
-	UpdatedAt      string         `json:"updated_at"`
-	IsLocked       bool           `json:"is_locked"`
-	LockedReason   *string        `json:"locked_reason"`
-	IsClosed       bool           `json:"is_closed"`
-	ApprovalStatus string         `json:"approval_status"`
-	IsBilled       bool           `json:"is_billed"`
-	TimerStartedAt *string        `json:"timer_started_at"`
-	StartedTime    *string        `json:"started_time"`
-	EndedTime      *string        `json:"ended_time"`
-	IsRunning      bool           `json:"is_running"`
-	Invoice        *interface{}   `json:"invoice"`
-	ExternalRef    *string        `json:"external_reference"`
-	Billable       bool           `json:"billable"`
-	Budgeted       bool           `json:"budgeted"`
-	BillableRate   float64        `json:"billable_rate"`
-	CostRate       float64        `json:"cost_rate"`
+type ListEntriesResponse struct {
+	TimeEntries []*TimeEntry `json:"time_entries"`
+	Pagination
 }
 
-type ListEntriesResponse struct {
-	Entries []*Entry `json:"entries"`
-	Total   int      `json:"total"`
+func (r *ListEntriesResponse) items() []*TimeEntry {
+	return r.TimeEntries
 }
 
 type ListAssignedProjectsResponse struct {
 	ProjectAssignments []*ProjectAssignment `json:"project_assignments"`
-	PerPage            int                  `json:"per_page"`
-	TotalPages         int                  `json:"total_pages"`
-	TotalEntries       int                  `json:"total_entries"`
-	NextPage           *int                 `json:"next_page"`
-	PreviousPage       *int                 `json:"previous_page"`
-	Page               int                  `json:"page"`
-	Links              Links                `json:"links"`
+	Pagination
+}
+
+func (r *ListAssignedProjectsResponse) items() []*ProjectAssignment {
+	return r.ProjectAssignments
 }
 
 type ProjectAssignment struct {
@@ -0,0 +1,13 @@
+package api
+
+import "context"
+
+// GetMe returns the user the token belongs to
+func (c *Client) GetMe(ctx context.Context) (*User, error) {
+	var user User
+	err := c.makeRequest(ctx, "GET", "/users/me", nil, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,290 @@
+// Package cache keeps the current user's project and task assignments on
+// disk between runs, so selectors, name resolution and shell completion do
+// not have to fetch them from Harvest every time. Recent entries are also
+// kept for a few minutes for the completion of entry IDs.
+//
+// Cached assignments are used as they are for the TTL. After that, only the
+// assignments updated since the last refresh are fetched and merged, until
+// the cache is older than MaxAge and is fetched again in full, which also
+// drops the projects the user was removed from.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"harvest-cli/internal/api"
+)
+
+const (
+	// DefaultTTL is how long cached assignments are used without asking
+	// Harvest for changes
+	DefaultTTL = time.Hour
+	// DefaultMaxAge is how long incremental refreshes are trusted before
+	// the assignments are fetched in full again
+	DefaultMaxAge = 7 * 24 * time.Hour
+)
+
+// Dir returns the cache directory of harvest-cli, under $XDG_CACHE_HOME
+// or the platform's equivalent
+func Dir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the cache directory: %w", err)
+	}
+	return filepath.Join(base, "harvest-cli"), nil
+}
+
+// Clear removes every cached file
+func Clear() error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", dir, err)
+	}
+	return nil
+}
+
+// Lister fetches the current user and their project assignments from
+// Harvest, implemented by *api.Client
+type Lister interface {
+	GetMe(ctx context.Context) (*api.User, error)
+	ListAssignedProjects(ctx context.Context, params api.ListParams) ([]*api.ProjectAssignment, error)
+}
+
+// AssignmentsOptions configures the assignment cache
+type AssignmentsOptions struct {
+	// Dir holds the cache files, Dir() by default
+	Dir string
+	// TTL defaults to DefaultTTL
+	TTL time.Duration
+	// MaxAge defaults to DefaultMaxAge
+	MaxAge time.Duration
+}
+
+// assignmentsFile is the on-disk format of the cache
+type assignmentsFile struct {
+	// FetchedAt is the time of the last full fetch
+	FetchedAt time.Time `json:"fetched_at"`
+	// RefreshedAt is the time of the last fetch, full or incremental
+	RefreshedAt time.Time                `json:"refreshed_at"`
+	Assignments []*api.ProjectAssignment `json:"assignments"`
+}
+
+// Assignments caches the project assignments of one user on disk. It can
+// be used in place of the *api.Client it wraps to list assignments.
+type Assignments struct {
+	lister Lister
+	owner  *owner
+	ttl    time.Duration
+	maxAge time.Duration
+
+	mu   sync.Mutex
+	data *assignmentsFile
+}
+
+// NewAssignments creates the cache of the assignments listed by lister.
+// The account ID and token, or a key standing for the token, tell the
+// cached users apart, the user of a new token being asked for the first
+// time it is used.
+func NewAssignments(lister Lister, accountId, token string, options AssignmentsOptions) (*Assignments, error) {
+	if options.Dir == "" {
+		dir, err := Dir()
+		if err != nil {
+			return nil, err
+		}
+		options.Dir = dir
+	}
+	if options.TTL == 0 {
+		options.TTL = DefaultTTL
+	}
+	if options.MaxAge == 0 {
+		options.MaxAge = DefaultMaxAge
+	}
+
+	return &Assignments{
+		lister: lister,
+		owner:  &owner{dir: options.Dir, kind: "assignments", accountId: accountId, token: token, getMe: lister.GetMe},
+		ttl:    options.TTL,
+		maxAge: options.MaxAge,
+	}, nil
+}
+
+// ListAssignedProjects returns the cached assignments, refreshing them
+// first when they are older than the TTL. Requests for a page or for
+// updated assignments are passed on to Harvest.
+func (a *Assignments) ListAssignedProjects(ctx context.Context, params api.ListParams) ([]*api.ProjectAssignment, error) {
+	if params != (api.ListParams{}) {
+		return a.lister.ListAssignedProjects(ctx, params)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.data == nil {
+		path, err := a.owner.resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		a.data = read(path)
+	}
+
+	now := time.Now()
+	switch {
+	case a.data == nil || now.Sub(a.data.FetchedAt) > a.maxAge:
+		if err := a.fetch(ctx); err != nil {
+			return nil, err
+		}
+	case now.Sub(a.data.RefreshedAt) > a.ttl:
+		if err := a.update(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return a.data.Assignments, nil
+}
+
+// Cached returns the cached assignments without contacting Harvest, or nil
+// when nothing is cached
+func (a *Assignments) Cached() []*api.ProjectAssignment {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.data == nil {
+		if path, ok := a.owner.known(); ok {
+			a.data = read(path)
+		}
+	}
+	if a.data == nil {
+		return nil
+	}
+	return a.data.Assignments
+}
+
+// Refresh fetches every assignment again, replacing the cached ones
+func (a *Assignments) Refresh(ctx context.Context) ([]*api.ProjectAssignment, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, err := a.owner.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if err := a.fetch(ctx); err != nil {
+		return nil, err
+	}
+	return a.data.Assignments, nil
+}
+
+// fetch replaces the cache with every assignment
+func (a *Assignments) fetch(ctx context.Context) error {
+	started := time.Now()
+	assignments, err := a.lister.ListAssignedProjects(ctx, api.ListParams{})
+	if err != nil {
+		return err
+	}
+
+	a.data = &assignmentsFile{
+		FetchedAt:   started,
+		RefreshedAt: started,
+		Assignments: active(assignments),
+	}
+	a.write()
+	return nil
+}
+
+// update merges the assignments changed since the last refresh
+func (a *Assignments) update(ctx context.Context) error {
+	started := time.Now()
+	since := a.data.RefreshedAt
+	changed, err := a.lister.ListAssignedProjects(ctx, api.ListParams{UpdatedSince: &since})
+	if err != nil {
+		return err
+	}
+
+	byID := map[int64]int{}
+	merged := a.data.Assignments
+	for i, assignment := range merged {
+		byID[assignment.ID] = i
+	}
+	for _, assignment := range changed {
+		if i, ok := byID[assignment.ID]; ok {
+			merged[i] = assignment
+		} else {
+			merged = append(merged, assignment)
+		}
+	}
+
+	a.data.Assignments = active(merged)
+	a.data.RefreshedAt = started
+	a.write()
+	return nil
+}
+
+// active drops the assignments that were deactivated
+func active(assignments []*api.ProjectAssignment) []*api.ProjectAssignment {
+	kept := []*api.ProjectAssignment{}
+	for _, assignment := range assignments {
+		if assignment.IsActive {
+			kept = append(kept, assignment)
+		}
+	}
+	return kept
+}
+
+// read loads the cache file at path, nil when it is missing or unreadable
+func read(path string) *assignmentsFile {
+	var data assignmentsFile
+	if !readFile(path, &data) {
+		return nil
+	}
+	return &data
+}
+
+// write saves the cache file of the resolved user
+func (a *Assignments) write() {
+	writeFile(a.owner.path(), a.data)
+}
+
+// readFile decodes the JSON file at path into v, reporting whether it
+// could
+func readFile(path string, v any) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(content, v) == nil
+}
+
+// writeFile saves v as JSON at path. Failing to do so only costs a fetch
+// on the next run, so errors are ignored.
+func writeFile(path string, v any) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+
+	// Write to a temporary file first so concurrent runs never read half a file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cache-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,188 @@
+package cache_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/api/fake"
+	"harvest-cli/internal/cache"
+)
+
+// newClient returns a client of the fake server with its current token
+func newClient(t *testing.T, srv *fake.Server) *api.Client {
+	t.Helper()
+	client, err := api.NewClient(srv.Token, srv.AccountID, api.ClientOptions{BaseURL: srv.BaseURL()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// newAssignments returns the assignment cache of the server's current token
+func newAssignments(t *testing.T, srv *fake.Server, options cache.AssignmentsOptions) *cache.Assignments {
+	t.Helper()
+	assignments, err := cache.NewAssignments(newClient(t, srv), srv.AccountID, srv.Token, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return assignments
+}
+
+func TestNewToken(t *testing.T) {
+	srv := fake.NewServer()
+	defer srv.Close()
+	srv.AddProject(api.ClientData{Name: "ACME"}, api.Project{Name: "Website"}, api.Task{Name: "Design"})
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	if _, err := newAssignments(t, srv, cache.AssignmentsOptions{Dir: dir}).ListAssignedProjects(ctx, api.ListParams{}); err != nil {
+		t.Fatalf("ListAssignedProjects: %v", err)
+	}
+	// Only a fetch would see the new project before the TTL
+	srv.AddProject(api.ClientData{Name: "ACME"}, api.Project{Name: "Mobile app"}, api.Task{Name: "Design"})
+
+	tests := []struct {
+		name  string
+		token string
+		user  api.User
+		// wantCached is whether the cache of the user is found without
+		// contacting Harvest
+		wantCached bool
+		// wantProjects is the number of assignments listed
+		wantProjects int
+	}{
+		{name: "same token", token: fake.DefaultToken, user: api.User{ID: 1}, wantCached: true, wantProjects: 1},
+		{name: "refreshed token of the same user", token: "refreshed", user: api.User{ID: 1}, wantProjects: 1},
+		{name: "refreshed token known", token: "refreshed", user: api.User{ID: 1}, wantCached: true, wantProjects: 1},
+		{name: "token of another user", token: "other", user: api.User{ID: 2}, wantProjects: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.SetToken(tt.token)
+			srv.SetUser(tt.user)
+			assignments := newAssignments(t, srv, cache.AssignmentsOptions{Dir: dir})
+
+			if cached := assignments.Cached(); (cached != nil) != tt.wantCached {
+				t.Errorf("Cached() = %d assignments, want them cached %v", len(cached), tt.wantCached)
+			}
+			listed, err := assignments.ListAssignedProjects(ctx, api.ListParams{})
+			if err != nil {
+				t.Fatalf("ListAssignedProjects: %v", err)
+			}
+			if len(listed) != tt.wantProjects {
+				t.Errorf("ListAssignedProjects() = %d assignments, want %d", len(listed), tt.wantProjects)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name string
+		// change modifies the assignments of the server after they were
+		// cached
+		change func(srv *fake.Server, website, mobile *api.ProjectAssignment)
+		// refresh fetches every assignment instead of the updated ones
+		refresh bool
+		// want are the names of the projects listed after the change
+		want []string
+	}{
+		{
+			name:   "nothing changed",
+			change: func(srv *fake.Server, website, mobile *api.ProjectAssignment) {},
+			want:   []string{"Website", "Mobile app"},
+		},
+		{
+			name: "renamed",
+			change: func(srv *fake.Server, website, mobile *api.ProjectAssignment) {
+				srv.ModifyProject(website.ID, func(p *api.ProjectAssignment) {
+					p.Project.Name = "Web shop"
+					p.UpdatedAt = time.Now().UTC()
+				})
+			},
+			want: []string{"Web shop", "Mobile app"},
+		},
+		{
+			name: "deactivated",
+			change: func(srv *fake.Server, website, mobile *api.ProjectAssignment) {
+				srv.ModifyProject(website.ID, func(p *api.ProjectAssignment) {
+					p.IsActive = false
+					p.UpdatedAt = time.Now().UTC()
+				})
+			},
+			want: []string{"Mobile app"},
+		},
+		{
+			name: "added",
+			change: func(srv *fake.Server, website, mobile *api.ProjectAssignment) {
+				srv.AddProject(api.ClientData{Name: "Globex"}, api.Project{Name: "Intranet"}, api.Task{Name: "Design"})
+			},
+			want: []string{"Website", "Mobile app", "Intranet"},
+		},
+		{
+			// Only what updated_since returns is merged
+			name: "changed without being updated",
+			change: func(srv *fake.Server, website, mobile *api.ProjectAssignment) {
+				srv.ModifyProject(mobile.ID, func(p *api.ProjectAssignment) {
+					p.Project.Name = "Mobile shop"
+					p.UpdatedAt = p.UpdatedAt.Add(-time.Hour)
+				})
+			},
+			want: []string{"Website", "Mobile app"},
+		},
+		{
+			name: "changed without being updated then refreshed",
+			change: func(srv *fake.Server, website, mobile *api.ProjectAssignment) {
+				srv.ModifyProject(mobile.ID, func(p *api.ProjectAssignment) {
+					p.Project.Name = "Mobile shop"
+					p.UpdatedAt = p.UpdatedAt.Add(-time.Hour)
+				})
+			},
+			refresh: true,
+			want:    []string{"Website", "Mobile shop"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fake.NewServer()
+			defer srv.Close()
+			website := srv.AddProject(api.ClientData{Name: "ACME"}, api.Project{Name: "Website"}, api.Task{Name: "Design"})
+			mobile := srv.AddProject(api.ClientData{Name: "ACME"}, api.Project{Name: "Mobile app"}, api.Task{Name: "Design"})
+			ctx := context.Background()
+
+			// Every listing asks Harvest for the updated assignments
+			assignments := newAssignments(t, srv, cache.AssignmentsOptions{Dir: t.TempDir(), TTL: time.Nanosecond})
+			if _, err := assignments.ListAssignedProjects(ctx, api.ListParams{}); err != nil {
+				t.Fatalf("ListAssignedProjects: %v", err)
+			}
+
+			tt.change(srv, website, mobile)
+			var listed []*api.ProjectAssignment
+			var err error
+			if tt.refresh {
+				listed, err = assignments.Refresh(ctx)
+			} else {
+				listed, err = assignments.ListAssignedProjects(ctx, api.ListParams{})
+			}
+			if err != nil {
+				t.Fatalf("listing after the change: %v", err)
+			}
+
+			var names []string
+			for _, assignment := range listed {
+				names = append(names, assignment.Project.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("got projects %q, want %q", names, tt.want)
+			}
+			if cached := assignments.Cached(); len(cached) != len(listed) {
+				t.Errorf("got %d cached assignments, want the %d listed", len(cached), len(listed))
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/dates"
+)
+
+const (
+	// DefaultEntriesTTL is how long recent entries are cached, kept short
+	// since most commands change them
+	DefaultEntriesTTL = 5 * time.Minute
+	// RecentDays is how far back recent entries go
+	RecentDays = 30
+)
+
+// EntryLister fetches the current user's time entries from Harvest,
+// implemented by *api.Client
+type EntryLister interface {
+	GetMe(ctx context.Context) (*api.User, error)
+	ListEntries(ctx context.Context, query api.TimeEntryQuery) ([]*api.TimeEntry, error)
+}
+
+// EntriesOptions configures the recent entries cache
+type EntriesOptions struct {
+	// Dir holds the cache files, Dir() by default
+	Dir string
+	// TTL defaults to DefaultEntriesTTL
+	TTL time.Duration
+}
+
+// entriesFile is the on-disk format of the recent entries
+type entriesFile struct {
+	FetchedAt time.Time        `json:"fetched_at"`
+	Entries   []*api.TimeEntry `json:"entries"`
+}
+
+// Entries caches the entries of the last RecentDays days on disk
+type Entries struct {
+	lister EntryLister
+	owner  *owner
+	ttl    time.Duration
+
+	mu   sync.Mutex
+	data *entriesFile
+}
+
+// NewEntries creates the cache of the recent entries listed by lister. The
+// account ID and token, or a key standing for the token, tell the cached
+// users apart, the user of a new token being asked for the first time it is
+// used.
+func NewEntries(lister EntryLister, accountId, token string, options EntriesOptions) (*Entries, error) {
+	if options.Dir == "" {
+		dir, err := Dir()
+		if err != nil {
+			return nil, err
+		}
+		options.Dir = dir
+	}
+	if options.TTL == 0 {
+		options.TTL = DefaultEntriesTTL
+	}
+
+	return &Entries{
+		lister: lister,
+		owner:  &owner{dir: options.Dir, kind: "entries", accountId: accountId, token: token, getMe: lister.GetMe},
+		ttl:    options.TTL,
+	}, nil
+}
+
+// Recent returns the current user's entries of the last RecentDays days,
+// most recent first, fetching them again when the cache is older than the TTL
+func (e *Entries) Recent(ctx context.Context) ([]*api.TimeEntry, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	path, err := e.owner.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if e.data == nil {
+		var data entriesFile
+		if readFile(path, &data) {
+			e.data = &data
+		}
+	}
+	if e.data != nil && time.Since(e.data.FetchedAt) <= e.ttl {
+		return e.data.Entries, nil
+	}
+
+	started := time.Now()
+	entries, err := e.lister.ListEntries(ctx, api.TimeEntryQuery{
+		UserId: e.owner.userId,
+		From:   started.AddDate(0, 0, -RecentDays).Format(dates.Layout),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	e.data = &entriesFile{FetchedAt: started, Entries: entries}
+	writeFile(path, e.data)
+	return entries, nil
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"harvest-cli/internal/api"
+)
+
+// usersFileName is the index of the users of the cached tokens
+const usersFileName = "users.json"
+
+// cachedUser is the user a token belongs to
+type cachedUser struct {
+	AccountId string `json:"account_id"`
+	UserId    int64  `json:"user_id"`
+	// SeenAt is when the token was first used
+	SeenAt time.Time `json:"seen_at"`
+}
+
+// owner finds the user whose cache files a cache reads. Files are named
+// after the account and user rather than the token, so that a refreshed or
+// rotated token keeps the cache of its user; the index from tokens to users
+// spares asking Harvest for the user on every run.
+type owner struct {
+	dir       string
+	kind      string
+	accountId string
+	token     string
+	getMe     func(ctx context.Context) (*api.User, error)
+
+	userId int64
+}
+
+// tokenKey hashes the account ID and token so that the token never ends up
+// on disk
+func tokenKey(accountId, token string) string {
+	sum := sha256.Sum256([]byte(accountId + ":" + token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// readUsers loads the index of dir, empty when it is missing or unreadable
+func readUsers(dir string) map[string]cachedUser {
+	users := map[string]cachedUser{}
+	if !readFile(filepath.Join(dir, usersFileName), &users) || users == nil {
+		return map[string]cachedUser{}
+	}
+	return users
+}
+
+// LookupUser returns the user of a token already used with the cache
+func LookupUser(accountId, token string) (int64, bool) {
+	dir, err := Dir()
+	if err != nil {
+		return 0, false
+	}
+	user, ok := readUsers(dir)[tokenKey(accountId, token)]
+	return user.UserId, ok
+}
+
+// ClearUser removes the cached files of one user of an account, along with
+// their tokens
+func ClearUser(accountId string, userId int64) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(dir, fmt.Sprintf("*-%s-%d.json", accountId, userId)))
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+
+	users := readUsers(dir)
+	for key, user := range users {
+		if user.AccountId == accountId && user.UserId == userId {
+			delete(users, key)
+		}
+	}
+	writeFile(filepath.Join(dir, usersFileName), users)
+	return nil
+}
+
+// known returns the path of the cache file when the user of the token is
+// already known, without contacting Harvest
+func (o *owner) known() (string, bool) {
+	if o.userId == 0 {
+		user, ok := readUsers(o.dir)[tokenKey(o.accountId, o.token)]
+		if !ok {
+			return "", false
+		}
+		o.userId = user.UserId
+	}
+	return o.path(), true
+}
+
+// resolve returns the path of the cache file, asking Harvest for the user
+// the first time a token is used
+func (o *owner) resolve(ctx context.Context) (string, error) {
+	if path, ok := o.known(); ok {
+		return path, nil
+	}
+
+	me, err := o.getMe(ctx)
+	if err != nil {
+		return "", err
+	}
+	o.userId = me.ID
+
+	// Forget the former tokens of the user after a while, as they expire.
+	// Concurrent runs may drop each other's tokens, which only costs asking
+	// for the user again.
+	now := time.Now()
+	users := readUsers(o.dir)
+	for key, user := range users {
+		if user.AccountId == o.accountId && user.UserId == o.userId && now.Sub(user.SeenAt) > DefaultMaxAge {
+			delete(users, key)
+		}
+	}
+	users[tokenKey(o.accountId, o.token)] = cachedUser{AccountId: o.accountId, UserId: o.userId, SeenAt: now}
+	writeFile(filepath.Join(o.dir, usersFileName), users)
+	return o.path(), nil
+}
+
+// path returns the path of the cache file of the resolved user
+func (o *owner) path() string {
+	return filepath.Join(o.dir, fmt.Sprintf("%s-%s-%d.json", o.kind, o.accountId, o.userId))
+}
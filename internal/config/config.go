@@ -1,44 +1,191 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"os"
-	"path/filepath"
+	"os/exec"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
 
+// DefaultProfile designates the top-level settings of the config file
+const DefaultProfile = "default"
+
+// TokenSourceCommand is the TokenSource of a token printed by token_command
+const TokenSourceCommand = "token_command"
+
 type Config struct {
-	Token  string `mapstructure:"token"`
-	AccountId  string `mapstructure:"account_id"`
+	Token     string `mapstructure:"token"`
+	AccountId string `mapstructure:"account_id"`
+	BaseURL   string `mapstructure:"base_url"`
+	// TokenCommand prints the token, e.g. "pass show harvest"
+	TokenCommand string `mapstructure:"token_command"`
+	// Passphrase opens sealed credentials without prompting for it
+	Passphrase string `mapstructure:"passphrase"`
+
+	// OAuth2 application used by `harvest auth login` instead of asking
+	// for a personal access token
+	OAuthClientId     string `mapstructure:"oauth_client_id"`
+	OAuthClientSecret string `mapstructure:"oauth_client_secret"`
+	OAuthRedirectPort int    `mapstructure:"oauth_redirect_port"`
+	// IdentityURL overrides the Harvest ID root, e.g. to point at a fake server
+	IdentityURL string `mapstructure:"identity_url"`
+
+	// Preferences overriding the defaults and the account settings
+	Output    string `mapstructure:"output"`
+	WeekStart string `mapstructure:"week_start"`
+	Rounding  string `mapstructure:"rounding"`
+	Theme     string `mapstructure:"theme"`
+
+	// Profile is the name of the profile in use, empty for the top-level
+	// settings
+	Profile string `mapstructure:"-"`
+	// TokenSource tells where the token was found, for `harvest auth status`.
+	// Token stays empty when it is TokenSourceCommand, see CommandToken.
+	TokenSource string `mapstructure:"-"`
+	// Credentials are the ones stored by `harvest auth login`, if any. When
+	// they are sealed, Token stays empty until they are opened.
+	Credentials *Credentials `mapstructure:"-"`
 }
 
-func Load() (*Config, error) {
+// envKeys binds settings to environment variables, which take precedence
+// over the config file and its profiles
+var envKeys = map[string]string{
+	"profile":             "HARVEST_PROFILE",
+	"token":               "HARVEST_TOKEN",
+	"account_id":          "HARVEST_ACCOUNT_ID",
+	"base_url":            "HARVEST_BASE_URL",
+	"token_command":       "HARVEST_TOKEN_COMMAND",
+	"passphrase":          "HARVEST_PASSPHRASE",
+	"oauth_client_id":     "HARVEST_OAUTH_CLIENT_ID",
+	"oauth_client_secret": "HARVEST_OAUTH_CLIENT_SECRET",
+	"identity_url":        "HARVEST_IDENTITY_URL",
+}
+
+// Load reads the settings of a profile, the one selected with
+// HARVEST_PROFILE or `harvest profile use` when it is empty. A profile's
+// settings override the top-level ones of the config file.
+func Load(profile string) (*Config, error) {
 
 	if err := godotenv.Load(); err != nil {
 		godotenv.Load(".env.local")
 	}
 
-	viper.SetConfigName("harvest-cli")
-	viper.SetConfigType("yaml")
-
-	home, _ := os.UserHomeDir()
-	viper.AddConfigPath(filepath.Join(home, ".config", "harvest-cli"))
-	viper.AddConfigPath(".")
-
-	viper.BindEnv("token", "HARVEST_TOKEN")
-	viper.BindEnv("account_id", "HARVEST_ACCOUNT_ID")
+	// A fresh instance keeps the settings of one profile from leaking into
+	// the next Load
+	v := viper.New()
+	v.SetConfigName("harvest-cli")
+	v.SetConfigType("yaml")
+	for _, dir := range configDirs() {
+		v.AddConfigPath(dir)
+	}
+	for key, env := range envKeys {
+		v.BindEnv(key, env)
+	}
 
 	var config Config
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, err
 		}
+	} else if err := checkFile(); err != nil {
+		return nil, err
 	}
 
-	if err := viper.Unmarshal(&config); err != nil {
+	if profile == "" {
+		profile = v.GetString("profile")
+	}
+	// Viper ignores the case of keys, so profile names too
+	profile = strings.ToLower(profile)
+	if profile != "" && profile != DefaultProfile {
+		settings, ok := v.GetStringMap("profiles")[profile].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q, see 'harvest profile list'", profile)
+		}
+		for key, value := range settings {
+			if env, ok := envKeys[key]; ok && os.Getenv(env) != "" {
+				continue
+			}
+			v.Set(key, value)
+		}
+		config.Profile = profile
+	}
+
+	if err := v.Unmarshal(&config); err != nil {
 		return nil, err
 	}
 
+	credentials, err := LoadCredentials(config.Profile)
+	if err != nil {
+		return nil, err
+	}
+	config.Credentials = credentials
+
+	// The token comes from the environment or config file first, then from
+	// token_command and last from the credentials stored by auth login.
+	// token_command is only run once the token is needed, as it may prompt
+	// to unlock a password manager, and shell completion loads the config on
+	// every keypress.
+	switch {
+	case config.Token != "":
+		config.TokenSource = "HARVEST_TOKEN"
+		if os.Getenv("HARVEST_TOKEN") == "" {
+			config.TokenSource = v.ConfigFileUsed()
+		}
+	case config.TokenCommand != "":
+		config.TokenSource = TokenSourceCommand
+	case credentials != nil:
+		config.Token = credentials.Token
+		config.TokenSource, _ = CredentialsPath(config.Profile)
+	}
+
+	if config.AccountId == "" && credentials != nil {
+		config.AccountId = credentials.AccountId
+	}
+
 	return &config, nil
 }
+
+// checkFile rejects invalid values in the config file, instead of failing
+// later in a less obvious way. Unknown keys are only reported by
+// `harvest config validate`.
+func checkFile() error {
+	file, err := ReadFile()
+	if err != nil {
+		return err
+	}
+	for _, problem := range file.Validate() {
+		if !problem.Unknown {
+			return fmt.Errorf("%s: %w, see 'harvest config validate'", file.Path(), problem)
+		}
+	}
+	return nil
+}
+
+// CommandToken runs the token_command with the shell and returns the first
+// line of its output, like password managers print the secret first
+func (c *Config) CommandToken(ctx context.Context) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.TokenCommand)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("token_command failed: %w: %s", err, message)
+		}
+		return "", fmt.Errorf("token_command failed: %w", err)
+	}
+
+	line, _, _ := strings.Cut(string(output), "\n")
+	token := strings.TrimSpace(line)
+	if token == "" {
+		return "", fmt.Errorf("token_command printed no token")
+	}
+	return token, nil
+}
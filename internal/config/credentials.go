@@ -0,0 +1,214 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pbkdf2Iterations is the cost of deriving a key from a passphrase
+const pbkdf2Iterations = 600_000
+
+// ErrWrongPassphrase is returned when sealed credentials cannot be opened
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// Credentials are the account ID and tokens stored by `harvest auth login`
+type Credentials struct {
+	AccountId string `json:"account_id"`
+	// UserId is the user the tokens belong to, whose cache is cleared on
+	// logout
+	UserId int64  `json:"user_id,omitempty"`
+	Token  string `json:"token,omitempty"`
+	// RefreshToken renews Token once it expires, for OAuth2 logins
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitzero"`
+	// Sealed holds the tokens encrypted with a passphrase instead of Token
+	// and RefreshToken
+	Sealed *SealedTokens `json:"sealed,omitempty"`
+}
+
+// SealedTokens are tokens encrypted with AES-GCM, using a key derived from
+// a passphrase with PBKDF2-SHA256
+type SealedTokens struct {
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// CredentialsPath returns the file holding the credentials stored for a
+// profile, empty for the top-level settings
+func CredentialsPath(profile string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the home directory: %w", err)
+	}
+	name := "credentials.json"
+	if profile != "" {
+		name = fmt.Sprintf("credentials-%s.json", profile)
+	}
+	return filepath.Join(home, ".config", "harvest-cli", name), nil
+}
+
+// LoadCredentials reads the credentials stored for a profile, nil when
+// there are none
+func LoadCredentials(profile string) (*Credentials, error) {
+	path, err := CredentialsPath(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var credentials Credentials
+	if err := json.Unmarshal(content, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &credentials, nil
+}
+
+// SaveCredentials stores the credentials of a profile in a file only the
+// user can read
+func SaveCredentials(profile string, credentials *Credentials) error {
+	path, err := CredentialsPath(profile)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(credentials, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	// Writing over an existing file would keep its mode until it is
+	// tightened, so the tokens go to a new file only the user can read,
+	// created with mode 0600, which then replaces the former one
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".credentials-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// RemoveCredentials deletes the credentials stored for a profile,
+// reporting whether there were any
+func RemoveCredentials(profile string) (bool, error) {
+	path, err := CredentialsPath(profile)
+	if err != nil {
+		return false, err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// tokens is the plaintext of SealedTokens
+type tokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// Seal returns a copy of the credentials to store, with the tokens
+// encrypted with the passphrase
+func (c *Credentials) Seal(passphrase string) (*Credentials, error) {
+	plaintext, err := json.Marshal(tokens{Token: c.Token, RefreshToken: c.RefreshToken})
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := passphraseCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return &Credentials{
+		AccountId: c.AccountId,
+		UserId:    c.UserId,
+		ExpiresAt: c.ExpiresAt,
+		Sealed: &SealedTokens{
+			Salt:       salt,
+			Nonce:      nonce,
+			Ciphertext: aead.Seal(nil, nonce, plaintext, []byte(c.AccountId)),
+		},
+	}, nil
+}
+
+// Open decrypts the sealed tokens with the passphrase into Token and
+// RefreshToken
+func (c *Credentials) Open(passphrase string) error {
+	if c.Sealed == nil {
+		return nil
+	}
+
+	aead, err := passphraseCipher(passphrase, c.Sealed.Salt)
+	if err != nil {
+		return err
+	}
+	plaintext, err := aead.Open(nil, c.Sealed.Nonce, c.Sealed.Ciphertext, []byte(c.AccountId))
+	if err != nil {
+		return ErrWrongPassphrase
+	}
+
+	var opened tokens
+	if err := json.Unmarshal(plaintext, &opened); err != nil {
+		return fmt.Errorf("failed to parse the sealed tokens: %w", err)
+	}
+	c.Token = opened.Token
+	c.RefreshToken = opened.RefreshToken
+	return nil
+}
+
+// passphraseCipher derives the AES-256-GCM cipher of a passphrase
+func passphraseCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultsFileName is the file setting the project and task of the
+// directory it is in and the ones below, usually at the root of a repository:
+//
+//	project: acme/website
+//	task: Development
+//	branches:
+//	  release/*:
+//	    task: QA
+//	  support-*:
+//	    project: ACME Support
+//	    task: Maintenance
+//
+// The override of the current git branch replaces the project and task it
+// sets. Branch names match exactly first, then as path.Match patterns, the
+// longest one winning.
+const DefaultsFileName = ".harvest.yaml"
+
+// Defaults are the project and task to log time to in a directory
+type Defaults struct {
+	// Project and Task designate the records like --project and --task do
+	Project string `yaml:"project"`
+	Task    string `yaml:"task"`
+
+	// Path is the file the defaults come from, empty when there is none
+	Path string `yaml:"-"`
+	// Branch is the pattern of the branch override applied, if any
+	Branch string `yaml:"-"`
+}
+
+// defaultsFile is the content of a DefaultsFileName
+type defaultsFile struct {
+	Project  string              `yaml:"project"`
+	Task     string              `yaml:"task"`
+	Branches map[string]Defaults `yaml:"branches"`
+}
+
+// FindDefaults looks for DefaultsFileName in dir and its parents and
+// returns the defaults for the current git branch, empty when there is no
+// such file
+func FindDefaults(dir string) (*Defaults, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, DefaultsFileName)
+		content, err := os.ReadFile(path)
+		if err == nil {
+			return parseDefaults(path, content, currentBranch(dir))
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return &Defaults{}, nil
+		}
+		dir = parent
+	}
+}
+
+func parseDefaults(filePath string, content []byte, branch string) (*Defaults, error) {
+	var file defaultsFile
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	// Typos would otherwise be silently ignored
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+
+	defaults := &Defaults{Project: file.Project, Task: file.Task, Path: filePath}
+	if branch == "" {
+		return defaults, nil
+	}
+
+	pattern, ok := "", false
+	if _, exact := file.Branches[branch]; exact {
+		pattern, ok = branch, true
+	} else {
+		for candidate := range file.Branches {
+			matched, err := path.Match(candidate, branch)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid branch pattern %q: %w", filePath, candidate, err)
+			}
+			if matched && (!ok || len(candidate) > len(pattern) ||
+				len(candidate) == len(pattern) && candidate < pattern) {
+				pattern, ok = candidate, true
+			}
+		}
+	}
+	if !ok {
+		return defaults, nil
+	}
+
+	override := file.Branches[pattern]
+	if override.Project != "" {
+		defaults.Project = override.Project
+	}
+	if override.Task != "" {
+		defaults.Task = override.Task
+	}
+	defaults.Branch = pattern
+	return defaults, nil
+}
+
+// currentBranch returns the git branch checked out in dir, empty outside a
+// repository or on a detached HEAD
+func currentBranch(dir string) string {
+	output, err := exec.Command("git", "-C", dir, "symbolic-ref", "--short", "-q", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
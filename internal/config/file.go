@@ -0,0 +1,190 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the config file, looked up in configDirs
+const FileName = "harvest-cli.yaml"
+
+// configDirs returns the directories searched for the config file, in order
+func configDirs() []string {
+	home, _ := os.UserHomeDir()
+	return []string{filepath.Join(home, ".config", "harvest-cli"), "."}
+}
+
+// FilePath returns the config file in use, or the one to create when there
+// is none
+func FilePath() string {
+	dirs := configDirs()
+	for _, dir := range dirs {
+		path := filepath.Join(dir, FileName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dirs[0], FileName)
+}
+
+// File is the YAML document of the config file. It is edited node by node
+// so that comments and the order of the keys are kept.
+type File struct {
+	path string
+	doc  yaml.Node
+}
+
+// ReadFile parses the config file, empty when it does not exist yet
+func ReadFile() (*File, error) {
+	f := &File{path: FilePath()}
+
+	content, err := os.ReadFile(f.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", f.path, err)
+	}
+	if err := yaml.Unmarshal(content, &f.doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", f.path, err)
+	}
+
+	if f.doc.Kind == 0 {
+		f.doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if f.root().Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: line %d: expected a mapping of settings", f.path, f.root().Line)
+	}
+	return f, nil
+}
+
+// Path returns the location of the file
+func (f *File) Path() string {
+	return f.path
+}
+
+func (f *File) root() *yaml.Node {
+	return f.doc.Content[0]
+}
+
+// Lookup returns the node at the path of keys, nil when it is not set
+func (f *File) Lookup(keys ...string) *yaml.Node {
+	node := f.root()
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		_, value := mappingEntry(node, key)
+		if value == nil {
+			return nil
+		}
+		node = value
+	}
+	return node
+}
+
+// Keys returns the keys of the mapping at the path, in file order
+func (f *File) Keys(keys ...string) []string {
+	node := f.Lookup(keys...)
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < len(node.Content); i += 2 {
+		names = append(names, node.Content[i].Value)
+	}
+	return names
+}
+
+// Set sets the value at the path of keys, creating the mappings leading to
+// it, with the type the schema gives to the last key. A nil value sets an
+// empty mapping.
+func (f *File) Set(value *string, keys ...string) error {
+	node := f.root()
+	for i, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s: line %d: cannot set %s in a %s", f.path, node.Line, key, node.ShortTag())
+		}
+
+		_, child := mappingEntry(node, key)
+		if child == nil {
+			// An empty mapping is read back as {}, keep it from growing inline
+			node.Style &^= yaml.FlowStyle
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+
+		if i == len(keys)-1 {
+			if value == nil {
+				if child.Kind != yaml.MappingNode {
+					*child = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				}
+			} else {
+				tag := "!!str"
+				if setting, ok := LookupSetting(key); ok {
+					tag = setting.tag()
+				}
+				// Keep the comments of the replaced value
+				*child = yaml.Node{
+					Kind:        yaml.ScalarNode,
+					Tag:         tag,
+					Value:       *value,
+					HeadComment: child.HeadComment,
+					LineComment: child.LineComment,
+				}
+			}
+		}
+		node = child
+	}
+	return nil
+}
+
+// Delete removes the value at the path of keys, reporting whether it was set
+func (f *File) Delete(keys ...string) bool {
+	if len(keys) == 0 {
+		return false
+	}
+
+	parent := f.Lookup(keys[:len(keys)-1]...)
+	if parent == nil || parent.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == keys[len(keys)-1] {
+			parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Write saves the file, readable only by the user since it may hold tokens
+func (f *File) Write() error {
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&f.doc); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", f.path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(f.path), err)
+	}
+	if err := os.WriteFile(f.path, b.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// mappingEntry returns the key and value nodes of key in a mapping
+func mappingEntry(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
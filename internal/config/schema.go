@@ -0,0 +1,246 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"harvest-cli/internal/duration"
+	"harvest-cli/internal/output"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kind is the type of the value of a setting
+type Kind string
+
+const (
+	String  Kind = "string"
+	Integer Kind = "integer"
+	URL     Kind = "url"
+	// Enum values are one of the Values of the setting
+	Enum Kind = "enum"
+)
+
+// Themes accepted by the theme setting
+const (
+	// ThemeAuto colors the prompts when the terminal supports it
+	ThemeAuto = "auto"
+	// ThemePlain never colors the prompts
+	ThemePlain = "plain"
+)
+
+// RoundingOff disables the rounding of durations
+const RoundingOff = "off"
+
+// Setting describes a key of the config file
+type Setting struct {
+	Key         string
+	Kind        Kind
+	Description string
+	// Values are the accepted values of an Enum, or suggestions for the
+	// other kinds
+	Values []string
+	// Secret values are masked when the settings are listed
+	Secret bool
+	// Global settings cannot be overridden by a profile
+	Global bool
+	// check validates the value beyond its kind
+	check func(value string) error
+}
+
+// Settings is the schema of the config file, in the order settings are
+// documented and asked for by `harvest config init`
+var Settings = []Setting{
+	{Key: "account_id", Kind: Integer, Description: "Harvest account ID"},
+	{Key: "token", Kind: String, Secret: true, Description: "Personal access token, prefer 'harvest auth login'"},
+	{Key: "token_command", Kind: String, Description: "Command printing the token, e.g. pass show harvest"},
+	{Key: "passphrase", Kind: String, Secret: true, Description: "Passphrase of encrypted credentials"},
+	{Key: "base_url", Kind: URL, Description: "Harvest API root"},
+	{Key: "oauth_client_id", Kind: String, Description: "OAuth2 application client ID"},
+	{Key: "oauth_client_secret", Kind: String, Secret: true, Description: "OAuth2 application client secret"},
+	{Key: "oauth_redirect_port", Kind: Integer, Description: "Loopback port of the OAuth2 redirect URL", check: checkPort},
+	{Key: "identity_url", Kind: URL, Description: "Harvest ID root"},
+	{Key: "output", Kind: String, Values: output.Formats[:len(output.Formats)-1],
+		Description: "Default output format: table, json, yaml, csv, tsv or go-template=...", check: checkOutput},
+	{Key: "week_start", Kind: Enum, Description: "First day of this-week and last-week, instead of the account's",
+		Values: []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}},
+	{Key: "rounding", Kind: String, Values: []string{RoundingOff, "6m", "15m", "30m"},
+		Description: "Round the durations of new and edited entries up to a multiple of this, e.g. 15m", check: checkRounding},
+	{Key: "theme", Kind: Enum, Values: []string{ThemeAuto, ThemePlain}, Description: "Colors of the prompts: auto or plain"},
+	{Key: "profile", Kind: String, Global: true, Description: "Profile used when --profile and HARVEST_PROFILE are not set"},
+}
+
+// LookupSetting returns the setting of a key
+func LookupSetting(key string) (Setting, bool) {
+	i := slices.IndexFunc(Settings, func(s Setting) bool { return s.Key == key })
+	if i < 0 {
+		return Setting{}, false
+	}
+	return Settings[i], true
+}
+
+// Validate checks a value against the setting
+func (s Setting) Validate(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("must not be empty")
+	}
+
+	switch s.Kind {
+	case Integer:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+	case URL:
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%q is not an http or https URL", value)
+		}
+	case Enum:
+		if !slices.Contains(s.Values, strings.ToLower(value)) {
+			return fmt.Errorf("%q is not one of %s", value, strings.Join(s.Values, ", "))
+		}
+	}
+
+	if s.check != nil {
+		return s.check(value)
+	}
+	return nil
+}
+
+// tag is the YAML tag of the values of the setting
+func (s Setting) tag() string {
+	if s.Kind == Integer {
+		return "!!int"
+	}
+	return "!!str"
+}
+
+func checkPort(value string) error {
+	port, _ := strconv.Atoi(value)
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("%s is not a port number", value)
+	}
+	return nil
+}
+
+func checkOutput(value string) error {
+	_, err := output.NewRenderer(value)
+	return err
+}
+
+func checkRounding(value string) error {
+	_, err := ParseRounding(value)
+	return err
+}
+
+// ParseRounding returns the increment in hours of the rounding setting,
+// zero when rounding is off
+func ParseRounding(value string) (float64, error) {
+	if value == "" || value == RoundingOff {
+		return 0, nil
+	}
+	hours, err := duration.Parse(value)
+	if err != nil {
+		return 0, err
+	}
+	if hours > 1 {
+		return 0, fmt.Errorf("rounding %q is longer than an hour", value)
+	}
+	return hours, nil
+}
+
+// Problem is an unknown or invalid setting of the config file
+type Problem struct {
+	Line int `json:"line"`
+	// Key is the path of the setting, e.g. profiles.client.account_id
+	Key     string `json:"key"`
+	Message string `json:"message"`
+	// Unknown tells the key is not part of the schema, e.g. a typo
+	Unknown bool `json:"unknown"`
+}
+
+func (p Problem) Error() string {
+	return fmt.Sprintf("line %d: %s: %s", p.Line, p.Key, p.Message)
+}
+
+// Validate checks the settings of the file against the schema
+func (f *File) Validate() []Problem {
+	var problems []Problem
+	root := f.root()
+	profiles := f.Keys("profiles")
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "profiles":
+			problems = append(problems, validateProfiles(value)...)
+		case "profile":
+			problems = append(problems, validateSetting(key.Value, key, value, false)...)
+			name := strings.ToLower(value.Value)
+			if value.Kind == yaml.ScalarNode && name != DefaultProfile && !slices.Contains(profiles, name) {
+				problems = append(problems, Problem{Line: value.Line, Key: key.Value, Message: fmt.Sprintf("unknown profile %q", value.Value)})
+			}
+		default:
+			problems = append(problems, validateSetting(key.Value, key, value, false)...)
+		}
+	}
+	return problems
+}
+
+func validateProfiles(profiles *yaml.Node) []Problem {
+	if profiles.Kind != yaml.MappingNode {
+		return []Problem{{Line: profiles.Line, Key: "profiles", Message: "expected a mapping of profiles"}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(profiles.Content); i += 2 {
+		name, settings := profiles.Content[i], profiles.Content[i+1]
+		path := "profiles." + name.Value
+		// Viper ignores the case of keys, so profile names too
+		if err := ValidateProfileName(strings.ToLower(name.Value)); err != nil {
+			problems = append(problems, Problem{Line: name.Line, Key: path, Message: err.Error()})
+		}
+		if settings.Kind != yaml.MappingNode {
+			problems = append(problems, Problem{Line: settings.Line, Key: path, Message: "expected a mapping of settings"})
+			continue
+		}
+		for j := 0; j+1 < len(settings.Content); j += 2 {
+			key := settings.Content[j]
+			problems = append(problems, validateSetting(path+"."+key.Value, key, settings.Content[j+1], true)...)
+		}
+	}
+	return problems
+}
+
+// validateSetting checks the value of a key, at path in the file
+func validateSetting(path string, key, value *yaml.Node, inProfile bool) []Problem {
+	setting, ok := LookupSetting(key.Value)
+	switch {
+	case !ok:
+		return []Problem{{Line: key.Line, Key: path, Message: "unknown setting", Unknown: true}}
+	case inProfile && setting.Global:
+		return []Problem{{Line: key.Line, Key: path, Message: "cannot be set in a profile"}}
+	case value.Kind != yaml.ScalarNode:
+		return []Problem{{Line: value.Line, Key: path, Message: "expected a single value"}}
+	}
+
+	if err := setting.Validate(value.Value); err != nil {
+		return []Problem{{Line: value.Line, Key: path, Message: err.Error()}}
+	}
+	return nil
+}
+
+// ValidateProfileName keeps profile names usable as YAML keys and in file
+// names
+func ValidateProfileName(name string) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("%q is the name of the top-level settings", name)
+	}
+	if name == "" || strings.Trim(name, "abcdefghijklmnopqrstuvwxyz0123456789-_") != "" {
+		return fmt.Errorf("invalid profile name %q, use lower-case letters, digits, - and _", name)
+	}
+	return nil
+}
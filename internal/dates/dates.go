@@ -0,0 +1,211 @@
+// Package dates resolves the date expressions accepted by --date, --from,
+// --to and the date prompt into calendar days.
+//
+// Accepted dates are:
+//
+//	2024-05-01                   an ISO 8601 date
+//	today, yesterday, tomorrow   relative to today
+//	mon, friday                  the latest such day, today included
+//	last friday                  the latest such day before today
+//	-2d, +1d, -1w                a number of days or weeks from today
+//
+// Ranges accept a single date, this-week, last-week, this-month,
+// last-month, or two dates separated by "..", e.g. 2024-05-01..2024-05-07.
+// Either side of ".." may be left empty for an open range.
+package dates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layout is the date format of the Harvest API
+const Layout = "2006-01-02"
+
+// Range is an inclusive range of days. A zero From or To leaves that side
+// of the range open.
+type Range struct {
+	From time.Time
+	To   time.Time
+}
+
+// FromISO returns the start of the range in the Harvest date format, or an
+// empty string when it is open
+func (r Range) FromISO() string {
+	return formatDay(r.From)
+}
+
+// ToISO returns the end of the range in the Harvest date format, or an
+// empty string when it is open
+func (r Range) ToISO() string {
+	return formatDay(r.To)
+}
+
+// String formats the range as it can be parsed back
+func (r Range) String() string {
+	if r.From.Equal(r.To) {
+		return r.FromISO()
+	}
+	return r.FromISO() + ".." + r.ToISO()
+}
+
+var offsetPattern = regexp.MustCompile(`^([+-])(\d+)\s*([dw])$`)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseWeekday reads a day name such as "Monday", as used by Harvest's
+// company week_start_day setting
+func ParseWeekday(s string) (time.Weekday, error) {
+	if day, ok := weekdays[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return day, nil
+	}
+	return 0, fmt.Errorf("unknown day %q", s)
+}
+
+// Parse resolves a date expression relative to now. The result is midnight
+// in now's location.
+func Parse(s string, now time.Time) (time.Time, error) {
+	input := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	if input == "" {
+		return time.Time{}, fmt.Errorf("date is empty")
+	}
+
+	date, ok := parse(input, day(now))
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid date %q, use e.g. yesterday, mon, last friday, -2d or YYYY-MM-DD", s)
+	}
+	return date, nil
+}
+
+func parse(input string, today time.Time) (time.Time, bool) {
+	switch input {
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	}
+
+	if date, err := time.ParseInLocation(Layout, input, today.Location()); err == nil {
+		return date, true
+	}
+
+	if weekday, ok := weekdays[input]; ok {
+		return today.AddDate(0, 0, -daysSince(today.Weekday(), weekday)), true
+	}
+
+	if name, ok := strings.CutPrefix(input, "last "); ok {
+		if weekday, ok := weekdays[name]; ok {
+			back := daysSince(today.Weekday(), weekday)
+			if back == 0 {
+				back = 7
+			}
+			return today.AddDate(0, 0, -back), true
+		}
+	}
+
+	if m := offsetPattern.FindStringSubmatch(input); m != nil {
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			return time.Time{}, false
+		}
+		if m[3] == "w" {
+			n *= 7
+		}
+		if m[1] == "-" {
+			n = -n
+		}
+		return today.AddDate(0, 0, n), true
+	}
+
+	return time.Time{}, false
+}
+
+// ParseRange resolves a range expression relative to now. Weeks start on
+// weekStart.
+func ParseRange(s string, now time.Time, weekStart time.Weekday) (Range, error) {
+	input := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	if input == "" {
+		return Range{}, fmt.Errorf("date range is empty")
+	}
+
+	today := day(now)
+	switch input {
+	case "this-week":
+		from := today.AddDate(0, 0, -daysSince(today.Weekday(), weekStart))
+		return Range{From: from, To: from.AddDate(0, 0, 6)}, nil
+	case "last-week":
+		from := today.AddDate(0, 0, -daysSince(today.Weekday(), weekStart)-7)
+		return Range{From: from, To: from.AddDate(0, 0, 6)}, nil
+	case "this-month":
+		from := today.AddDate(0, 0, 1-today.Day())
+		return Range{From: from, To: from.AddDate(0, 1, -1)}, nil
+	case "last-month":
+		from := today.AddDate(0, 0, 1-today.Day()).AddDate(0, -1, 0)
+		return Range{From: from, To: from.AddDate(0, 1, -1)}, nil
+	}
+
+	start, end, isRange := strings.Cut(input, "..")
+	if !isRange {
+		date, err := Parse(input, now)
+		if err != nil {
+			return Range{}, err
+		}
+		return Range{From: date, To: date}, nil
+	}
+
+	var r Range
+	var err error
+	if start = strings.TrimSpace(start); start != "" {
+		if r.From, err = Parse(start, now); err != nil {
+			return Range{}, err
+		}
+	}
+	if end = strings.TrimSpace(end); end != "" {
+		if r.To, err = Parse(end, now); err != nil {
+			return Range{}, err
+		}
+	}
+	if start == "" && end == "" {
+		return Range{}, fmt.Errorf("invalid date range %q, at least one side is required", s)
+	}
+	if !r.From.IsZero() && !r.To.IsZero() && r.To.Before(r.From) {
+		return Range{}, fmt.Errorf("invalid date range %q, it ends before it starts", s)
+	}
+	return r, nil
+}
+
+// Describe formats a resolved date for previews, e.g. "Fri, 10 May 2024"
+func Describe(date time.Time) string {
+	return date.Format("Mon, 02 Jan 2006")
+}
+
+// daysSince returns how many days ago the latest weekday was, 0 for today
+func daysSince(today, weekday time.Weekday) int {
+	return (int(today) - int(weekday) + 7) % 7
+}
+
+// day truncates t to midnight in its location
+func day(t time.Time) time.Time {
+	year, month, d := t.Date()
+	return time.Date(year, month, d, 0, 0, 0, 0, t.Location())
+}
+
+func formatDay(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(Layout)
+}
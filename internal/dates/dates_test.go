@@ -0,0 +1,117 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+// wednesday is the now of the tests, Wednesday 15 May 2024
+var wednesday = time.Date(2024, time.May, 15, 10, 30, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		// want is the date in the Harvest format, empty when the input is
+		// rejected
+		want string
+	}{
+		{input: "today", want: "2024-05-15"},
+		{input: "yesterday", want: "2024-05-14"},
+		{input: "Tomorrow", want: "2024-05-16"},
+		{input: "2024-05-01", want: "2024-05-01"},
+		{input: "mon", want: "2024-05-13"},
+		{input: "wed", want: "2024-05-15"},
+		{input: "thursday", want: "2024-05-09"},
+		{input: "last mon", want: "2024-05-13"},
+		{input: "last wed", want: "2024-05-08"},
+		{input: " Last  Friday ", want: "2024-05-10"},
+		{input: "-2d", want: "2024-05-13"},
+		{input: "+1d", want: "2024-05-16"},
+		{input: "-1w", want: "2024-05-08"},
+		{input: "-2 d", want: "2024-05-13"},
+		{input: "-0d", want: "2024-05-15"},
+		{input: ""},
+		{input: "someday"},
+		{input: "last"},
+		{input: "last month"},
+		{input: "2024-02-30"},
+		{input: "2d"},
+		{input: "-2m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input, wednesday)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("Parse(%q) = %s, want an error", tt.input, got.Format(Layout))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.input, err)
+			}
+			if got.Format(Layout) != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, got.Format(Layout), tt.want)
+			}
+			if got.Hour() != 0 || got.Minute() != 0 || got.Location() != time.UTC {
+				t.Errorf("Parse(%q) = %v, want midnight in the location of now", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		input string
+		// now defaults to wednesday
+		now       time.Time
+		weekStart time.Weekday
+		// wantFrom and wantTo are empty for an open side
+		wantFrom string
+		wantTo   string
+		wantErr  bool
+	}{
+		{input: "today", wantFrom: "2024-05-15", wantTo: "2024-05-15"},
+		{input: "this-week", weekStart: time.Monday, wantFrom: "2024-05-13", wantTo: "2024-05-19"},
+		{input: "this-week", weekStart: time.Sunday, wantFrom: "2024-05-12", wantTo: "2024-05-18"},
+		{input: "this-week", weekStart: time.Wednesday, wantFrom: "2024-05-15", wantTo: "2024-05-21"},
+		{input: "last-week", weekStart: time.Monday, wantFrom: "2024-05-06", wantTo: "2024-05-12"},
+		{input: "this-month", wantFrom: "2024-05-01", wantTo: "2024-05-31"},
+		{input: "this-month", now: time.Date(2024, time.February, 29, 23, 0, 0, 0, time.UTC), wantFrom: "2024-02-01", wantTo: "2024-02-29"},
+		{input: "last-month", wantFrom: "2024-04-01", wantTo: "2024-04-30"},
+		{input: "last-month", now: time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC), wantFrom: "2024-02-01", wantTo: "2024-02-29"},
+		{input: "last-month", now: time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC), wantFrom: "2023-12-01", wantTo: "2023-12-31"},
+		{input: "2024-05-01..2024-05-07", wantFrom: "2024-05-01", wantTo: "2024-05-07"},
+		{input: "last mon .. -1d", wantFrom: "2024-05-13", wantTo: "2024-05-14"},
+		{input: "2024-05-01..", wantFrom: "2024-05-01"},
+		{input: "..yesterday", wantTo: "2024-05-14"},
+		{input: "..", wantErr: true},
+		{input: "", wantErr: true},
+		{input: "2024-05-07..2024-05-01", wantErr: true},
+		{input: "2024-05-01..someday", wantErr: true},
+		{input: "next-week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			now := tt.now
+			if now.IsZero() {
+				now = wednesday
+			}
+			got, err := ParseRange(tt.input, now, tt.weekStart)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseRange(%q) = %s, want an error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRange(%q): %v", tt.input, err)
+			}
+			if got.FromISO() != tt.wantFrom || got.ToISO() != tt.wantTo {
+				t.Errorf("ParseRange(%q) = %q..%q, want %q..%q", tt.input, got.FromISO(), got.ToISO(), tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Package duration parses and formats the durations of time entries.
+//
+// Durations are expressed in decimal hours, like the Harvest API does.
+// Accepted inputs are:
+//
+//	1h30m, 2h 15m, 45m, 2h   hours and minutes with units
+//	1:30                     hours and minutes
+//	1.5, 1,5, 8              decimal hours
+//	90                       minutes, for whole numbers above MaxHours
+package duration
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format is how an account displays durations, using the values of
+// Harvest's company time_format setting
+type Format string
+
+const (
+	// Decimal shows durations as decimal hours, e.g. 1.50
+	Decimal Format = "decimal"
+	// HoursMinutes shows durations as hours and minutes, e.g. 1:30
+	HoursMinutes Format = "hours_minutes"
+)
+
+// MaxHours is the longest duration of a single time entry
+const MaxHours = 24
+
+var (
+	unitsPattern   = regexp.MustCompile(`^(?:(\d+(?:[.,]\d+)?)\s*h)?\s*(?:(\d+(?:[.,]\d+)?)\s*m)?$`)
+	clockPattern   = regexp.MustCompile(`^(\d+):([0-5]\d)$`)
+	decimalPattern = regexp.MustCompile(`^\d+(?:[.,]\d+)?$`)
+	integerPattern = regexp.MustCompile(`^\d+$`)
+)
+
+// ParseFormat validates a time format name
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case Decimal, HoursMinutes:
+		return Format(s), nil
+	}
+	return "", fmt.Errorf("unknown time format %q, expected %q or %q", s, Decimal, HoursMinutes)
+}
+
+// Parse converts a duration typed by the user into decimal hours. It
+// rejects zero and durations longer than a day.
+func Parse(s string) (float64, error) {
+	input := strings.ToLower(strings.TrimSpace(s))
+	if input == "" {
+		return 0, fmt.Errorf("duration is empty")
+	}
+
+	hours, ok := parse(input)
+	if !ok {
+		return 0, fmt.Errorf("invalid duration %q, use e.g. 1h30m, 1:30, 1.5 or 90", s)
+	}
+	if hours <= 0 {
+		return 0, fmt.Errorf("duration must be longer than zero")
+	}
+	if hours > MaxHours {
+		return 0, fmt.Errorf("duration %q is longer than %d hours", s, MaxHours)
+	}
+	return hours, nil
+}
+
+func parse(input string) (float64, bool) {
+	if m := clockPattern.FindStringSubmatch(input); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		min, _ := strconv.Atoi(m[2])
+		return float64(h) + float64(min)/60, true
+	}
+
+	// Whole numbers are hours up to a day, minutes above, so that both 8
+	// and 90 mean what they usually do. Fractions are always hours.
+	if integerPattern.MatchString(input) {
+		n, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return 0, false
+		}
+		if n > MaxHours {
+			return n / 60, true
+		}
+		return n, true
+	}
+	if decimalPattern.MatchString(input) {
+		f, err := strconv.ParseFloat(strings.Replace(input, ",", ".", 1), 64)
+		return f, err == nil
+	}
+
+	if m := unitsPattern.FindStringSubmatch(input); m != nil && (m[1] != "" || m[2] != "") {
+		var hours float64
+		if m[1] != "" {
+			h, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+			if err != nil {
+				return 0, false
+			}
+			hours = h
+		}
+		if m[2] != "" {
+			min, err := strconv.ParseFloat(strings.Replace(m[2], ",", ".", 1), 64)
+			if err != nil {
+				return 0, false
+			}
+			hours += min / 60
+		}
+		return hours, true
+	}
+
+	return 0, false
+}
+
+// Round rounds hours to the precision of the format: hundredths of an hour
+// for decimal, whole minutes for hours and minutes
+func Round(hours float64, format Format) float64 {
+	if format == HoursMinutes {
+		return math.Round(hours*60) / 60
+	}
+	return math.Round(hours*100) / 100
+}
+
+// RoundUp rounds hours up to the next multiple of increment, e.g. 0.25 for
+// quarters of an hour. A zero increment leaves hours unchanged.
+func RoundUp(hours, increment float64) float64 {
+	if increment <= 0 {
+		return hours
+	}
+	// Tolerate the imprecision of hours already on a multiple
+	return math.Ceil(hours/increment-1e-9) * increment
+}
+
+// FormatHours formats decimal hours for display, e.g. 1.50 or 1:30
+func FormatHours(hours float64, format Format) string {
+	if format == HoursMinutes {
+		minutes := int(math.Round(hours * 60))
+		return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+	}
+	return strconv.FormatFloat(Round(hours, Decimal), 'f', 2, 64)
+}
@@ -0,0 +1,106 @@
+package duration
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		// wantErr tells the input is rejected
+		wantErr bool
+	}{
+		{input: "1h30m", want: 1.5},
+		{input: "2h 15m", want: 2.25},
+		{input: "45m", want: 0.75},
+		{input: "90m", want: 1.5},
+		{input: "7.5m", want: 0.125},
+		{input: "1.5h", want: 1.5},
+		{input: "2H", want: 2},
+		{input: "1:30", want: 1.5},
+		{input: "0:05", want: 5.0 / 60},
+		{input: "1.5", want: 1.5},
+		{input: "1,5", want: 1.5},
+		{input: " 8 ", want: 8},
+		{input: "15", want: 15},
+		{input: "24", want: 24},
+		{input: "25", want: 25.0 / 60},
+		{input: "90", want: 1.5},
+		{input: "1440", want: 24},
+		{input: "1441", wantErr: true},
+		{input: "24.5", wantErr: true},
+		{input: "24h1m", wantErr: true},
+		{input: "0", wantErr: true},
+		{input: "0m", wantErr: true},
+		{input: "", wantErr: true},
+		{input: "h", wantErr: true},
+		{input: "1:60", wantErr: true},
+		{input: "-1", wantErr: true},
+		{input: "nan", wantErr: true},
+		{input: "NaN", wantErr: true},
+		{input: "inf", wantErr: true},
+		{input: "+Inf", wantErr: true},
+		{input: "1e1", wantErr: true},
+		{input: "0x1p1", wantErr: true},
+		{input: ".5", wantErr: true},
+		{input: "1.", wantErr: true},
+		{input: "1.5.2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %v, want an error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Parse(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatHours(t *testing.T) {
+	tests := []struct {
+		hours  float64
+		format Format
+		want   string
+	}{
+		{hours: 1.5, format: Decimal, want: "1.50"},
+		{hours: 1.005, format: Decimal, want: "1.00"},
+		{hours: 1.5, format: HoursMinutes, want: "1:30"},
+		{hours: 0.25, format: HoursMinutes, want: "0:15"},
+		{hours: 59.9 / 60, format: HoursMinutes, want: "1:00"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatHours(tt.hours, tt.format); got != tt.want {
+			t.Errorf("FormatHours(%v, %s) = %q, want %q", tt.hours, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestRoundUp(t *testing.T) {
+	tests := []struct {
+		hours, increment, want float64
+	}{
+		{hours: 1 + 5.0/60, increment: 0.25, want: 1.25},
+		{hours: 1.25, increment: 0.25, want: 1.25},
+		{hours: 0.7, increment: 0.1, want: 0.7},
+		{hours: 1.01, increment: 0, want: 1.01},
+	}
+
+	for _, tt := range tests {
+		if got := RoundUp(tt.hours, tt.increment); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("RoundUp(%v, %v) = %v, want %v", tt.hours, tt.increment, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package output renders command results in the format chosen with --output
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats accepted by NewRenderer
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatCSV   = "csv"
+	FormatTSV   = "tsv"
+
+	// templatePrefix introduces a Go template, e.g. go-template={{.id}}
+	templatePrefix = "go-template="
+)
+
+// Formats lists the accepted formats, for help texts and completion
+var Formats = []string{FormatTable, FormatJSON, FormatYAML, FormatCSV, FormatTSV, templatePrefix + "..."}
+
+// Table is the tabular view of a value
+type Table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// Tabular is implemented by values that can be shown as a table, CSV or TSV
+type Tabular interface {
+	Table() Table
+}
+
+// Renderer writes values in a single format
+type Renderer struct {
+	format   string
+	template *template.Template
+}
+
+// NewRenderer parses an --output value. An empty format renders tables.
+func NewRenderer(format string) (*Renderer, error) {
+	if format == "" {
+		format = FormatTable
+	}
+
+	if text, ok := strings.CutPrefix(format, templatePrefix); ok {
+		tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid go-template: %w", err)
+		}
+		return &Renderer{format: templatePrefix, template: tmpl}, nil
+	}
+
+	switch format {
+	case FormatTable, FormatJSON, FormatYAML, FormatCSV, FormatTSV:
+		return &Renderer{format: format}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q, expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// Render writes v to w. The table, csv and tsv formats require v to
+// implement Tabular; the other formats use the JSON encoding of v, so
+// templates refer to fields by their JSON names, e.g. {{.spent_date}}.
+func (r *Renderer) Render(w io.Writer, v any) error {
+	switch r.format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case FormatYAML:
+		return renderYAML(w, v)
+	case templatePrefix:
+		return r.renderTemplate(w, v)
+	}
+
+	tabular, ok := v.(Tabular)
+	if !ok {
+		return fmt.Errorf("output format %q is not supported here", r.format)
+	}
+	table := tabular.Table()
+
+	switch r.format {
+	case FormatCSV:
+		return renderDelimited(w, table, ',')
+	case FormatTSV:
+		return renderDelimited(w, table, '\t')
+	}
+	return renderTable(w, table)
+}
+
+func renderTable(w io.Writer, table Table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	headers := make([]string, len(table.Headers))
+	for i, header := range table.Headers {
+		headers[i] = strings.ToUpper(header)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, row := range table.Rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			// Tabs and newlines would break the alignment
+			cells[i] = strings.Join(strings.Fields(cell), " ")
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func renderDelimited(w io.Writer, table Table, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	if err := writer.Write(table.Headers); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(table.Rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func renderYAML(w io.Writer, v any) error {
+	// Going through JSON reuses the json tags and keeps the field order
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(jsonBytes, &node); err != nil {
+		return err
+	}
+	clearStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// clearStyle drops the flow style and quoting inherited from the JSON input
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+func (r *Renderer) renderTemplate(w io.Writer, v any) error {
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// UseNumber keeps ids from being printed in scientific notation
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+
+	var data any
+	if err := decoder.Decode(&data); err != nil {
+		return err
+	}
+
+	return r.template.Execute(w, data)
+}
@@ -0,0 +1,100 @@
+// Package resolve finds the projects and tasks designated on the command
+// line by ID, code, name or a fuzzy string.
+//
+// A query is matched in order against:
+//
+//	123                  the ID
+//	WEB, Website         a code or name, ignoring case
+//	acme/website         a client and project name
+//	acmweb               a fuzzy match of "Client/Project CODE"
+//
+// The first step with matches wins, so an exact name is never ambiguous
+// with the names it is a fuzzy match of.
+package resolve
+
+import (
+	"strconv"
+	"strings"
+
+	"harvest-cli/internal/api"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// Matcher describes how the records of type T are designated
+type Matcher[T any] struct {
+	// ID returns the ID of a record
+	ID func(T) int64
+	// Keys returns the values matched exactly, ignoring case
+	Keys func(T) []string
+	// Label returns the text matched fuzzily, also used to list candidates
+	Label func(T) string
+}
+
+// Find returns the records matching query, best match first. Several
+// records mean the query is ambiguous.
+func (m Matcher[T]) Find(query string, records []T) []T {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	if id, err := strconv.ParseInt(query, 10, 64); err == nil {
+		for _, record := range records {
+			if m.ID(record) == id {
+				return []T{record}
+			}
+		}
+	}
+
+	var exact []T
+	for _, record := range records {
+		for _, key := range m.Keys(record) {
+			if key != "" && strings.EqualFold(key, query) {
+				exact = append(exact, record)
+				break
+			}
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+
+	labels := make([]string, len(records))
+	for i, record := range records {
+		labels[i] = m.Label(record)
+	}
+	var matches []T
+	for _, match := range fuzzy.Find(query, labels) {
+		matches = append(matches, records[match.Index])
+	}
+	return matches
+}
+
+// Projects designates project assignments by project ID, code, name or
+// client/project name
+var Projects = Matcher[*api.ProjectAssignment]{
+	ID: func(p *api.ProjectAssignment) int64 { return p.Project.ID },
+	Keys: func(p *api.ProjectAssignment) []string {
+		return []string{
+			p.Project.Code,
+			p.Project.Name,
+			p.Client.Name + "/" + p.Project.Name,
+			p.Client.Name + " / " + p.Project.Name,
+		}
+	},
+	Label: func(p *api.ProjectAssignment) string {
+		label := p.Client.Name + "/" + p.Project.Name
+		if p.Project.Code != "" {
+			label += " " + p.Project.Code
+		}
+		return label
+	},
+}
+
+// Tasks designates task assignments by task ID or name
+var Tasks = Matcher[*api.TaskAssignment]{
+	ID:    func(t *api.TaskAssignment) int64 { return t.Task.ID },
+	Keys:  func(t *api.TaskAssignment) []string { return []string{t.Task.Name} },
+	Label: func(t *api.TaskAssignment) string { return t.Task.Name },
+}
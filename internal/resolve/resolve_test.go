@@ -0,0 +1,94 @@
+package resolve
+
+import (
+	"slices"
+	"testing"
+
+	"harvest-cli/internal/api"
+)
+
+func project(id int64, client, name, code string) *api.ProjectAssignment {
+	return &api.ProjectAssignment{
+		Project: api.Project{ID: id, Name: name, Code: code},
+		Client:  api.ClientData{Name: client},
+	}
+}
+
+var projects = []*api.ProjectAssignment{
+	project(10, "ACME", "Website", "WEB"),
+	project(11, "ACME", "Website Redesign", "WEBR"),
+	project(12, "Globex", "Mobile app", "MOB"),
+	project(13, "Initech", "Website", ""),
+}
+
+func TestProjects(t *testing.T) {
+	tests := []struct {
+		query string
+		// want are the IDs of the matches in any order, several meaning the
+		// query is ambiguous
+		want []int64
+	}{
+		{query: "12", want: []int64{12}},
+		{query: "web", want: []int64{10}},
+		{query: " WEBR ", want: []int64{11}},
+		{query: "acme/website", want: []int64{10}},
+		{query: "ACME / Website", want: []int64{10}},
+		{query: "initech/website", want: []int64{13}},
+		// Two projects share the name
+		{query: "website", want: []int64{10, 13}},
+		// Fuzzy matches
+		{query: "glbmob", want: []int64{12}},
+		{query: "redesign", want: []int64{11}},
+		{query: "acmweb", want: []int64{10, 11}},
+		{query: "webste", want: []int64{10, 11, 13}},
+		{query: "99"},
+		{query: "zzz"},
+		{query: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []int64
+			for _, match := range Projects.Find(tt.query, projects) {
+				got = append(got, match.Project.ID)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Projects.Find(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTasks(t *testing.T) {
+	tasks := []*api.TaskAssignment{
+		{Task: api.Task{ID: 1, Name: "Design"}},
+		{Task: api.Task{ID: 2, Name: "Design review"}},
+		{Task: api.Task{ID: 3, Name: "QA"}},
+	}
+
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{query: "3", want: []int64{3}},
+		{query: "design", want: []int64{1}},
+		{query: "qa", want: []int64{3}},
+		{query: "des", want: []int64{1, 2}},
+		{query: "dsgnrev", want: []int64{2}},
+		{query: "4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []int64
+			for _, match := range Tasks.Find(tt.query, tasks) {
+				got = append(got, match.Task.ID)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Tasks.Find(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
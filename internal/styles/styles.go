@@ -1,6 +1,9 @@
 package styles
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
 
 var (
 	// Colors
@@ -22,3 +25,8 @@ var (
 			Background(PrimaryColor).
 			Padding(0, 1)
 )
+
+// DisableColors renders every style without colors, for the plain theme
+func DisableColors() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
@@ -0,0 +1,296 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/dates"
+	"harvest-cli/internal/duration"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles for the calendar mode of the date input
+var (
+	calendarCursorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FAFAFA")).
+				Background(lipgloss.Color("#7D56F4"))
+
+	calendarTodayStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("205")).
+				Bold(true)
+
+	calendarOtherMonthStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("238"))
+)
+
+// calendarCellWidth is the width of a day column, wide enough for "10:30"
+const calendarCellWidth = 6
+
+// DayHoursLoader loads the hours already logged on each day between from
+// and to, keyed by date in YYYY-MM-DD format
+type DayHoursLoader interface {
+	Load(ctx context.Context, from, to time.Time) (map[string]float64, error)
+}
+
+// CalendarOptions configures the calendar mode of the date input
+type CalendarOptions struct {
+	// Loader annotates the days with the hours already logged, if set
+	Loader DayHoursLoader
+	// WeekStart is the day shown in the first column
+	WeekStart time.Weekday
+	// HoursFormat is how the logged hours are displayed
+	HoursFormat duration.Format
+	// Open starts the input in calendar mode instead of text mode
+	Open bool
+}
+
+type dayHoursLoadedMsg struct {
+	month time.Time
+	hours map[string]float64
+}
+
+type dayHoursErrorMsg struct {
+	month time.Time
+	err   error
+}
+
+// calendar is the month grid shown by DateInputModel in calendar mode
+type calendar struct {
+	options CalendarOptions
+	ctx     context.Context
+	cursor  time.Time
+	hours   map[string]float64
+	// loaded holds the months whose hours were requested, by YYYY-MM
+	loaded  map[string]bool
+	loading bool
+	err     error
+}
+
+func newCalendar(ctx context.Context, options CalendarOptions) calendar {
+	if options.HoursFormat == "" {
+		options.HoursFormat = duration.Decimal
+	}
+
+	return calendar{
+		options: options,
+		ctx:     ctx,
+		cursor:  today(),
+		hours:   map[string]float64{},
+		loaded:  map[string]bool{},
+	}
+}
+
+// today returns midnight of the current day
+func today() time.Time {
+	year, month, day := time.Now().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// firstOfMonth returns the first day of date's month
+func firstOfMonth(date time.Time) time.Time {
+	return date.AddDate(0, 0, 1-date.Day())
+}
+
+// addMonths moves date by n months, keeping to the last day of the target
+// month rather than overflowing into the next one, e.g. from 31 January
+func addMonths(date time.Time, n int) time.Time {
+	target := firstOfMonth(date).AddDate(0, n, 0)
+	last := target.AddDate(0, 1, -1).Day()
+	return target.AddDate(0, 0, min(date.Day(), last)-1)
+}
+
+// moveTo moves the cursor to date, loading the hours of its month when
+// they were not requested yet
+func (c *calendar) moveTo(date time.Time) tea.Cmd {
+	c.cursor = date
+	return c.loadMonth()
+}
+
+func (c *calendar) loadMonth() tea.Cmd {
+	month := firstOfMonth(c.cursor)
+	key := month.Format("2006-01")
+	if c.options.Loader == nil || c.loaded[key] {
+		return nil
+	}
+	c.loaded[key] = true
+	c.loading = true
+
+	loader, ctx := c.options.Loader, c.ctx
+	return func() tea.Msg {
+		hours, err := loader.Load(ctx, month, month.AddDate(0, 1, -1))
+		if err != nil {
+			return dayHoursErrorMsg{month: month, err: err}
+		}
+		return dayHoursLoadedMsg{month: month, hours: hours}
+	}
+}
+
+// update handles the keys of calendar mode. It reports whether the
+// highlighted day was picked.
+func (c *calendar) update(msg tea.Msg) (bool, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			return true, nil
+		case "left", "h":
+			return false, c.moveTo(c.cursor.AddDate(0, 0, -1))
+		case "right", "l":
+			return false, c.moveTo(c.cursor.AddDate(0, 0, 1))
+		case "up", "k":
+			return false, c.moveTo(c.cursor.AddDate(0, 0, -7))
+		case "down", "j":
+			return false, c.moveTo(c.cursor.AddDate(0, 0, 7))
+		case "pgup":
+			return false, c.moveTo(addMonths(c.cursor, -1))
+		case "pgdown":
+			return false, c.moveTo(addMonths(c.cursor, 1))
+		case "t":
+			return false, c.moveTo(today())
+		}
+
+	case dayHoursLoadedMsg:
+		c.loading = false
+		for date, hours := range msg.hours {
+			c.hours[date] = hours
+		}
+
+	case dayHoursErrorMsg:
+		c.loading = false
+		c.err = msg.err
+		// Allow another attempt when the month is shown again
+		delete(c.loaded, msg.month.Format("2006-01"))
+	}
+	return false, nil
+}
+
+// view renders the month of the cursor, one row of day numbers and one
+// row of logged hours per week
+func (c calendar) view() string {
+	var b strings.Builder
+
+	month := firstOfMonth(c.cursor)
+	b.WriteString(titleStyle.Render(month.Format("January 2006")))
+	b.WriteString("\n\n")
+
+	for i := range 7 {
+		name := time.Weekday((int(c.options.WeekStart) + i) % 7).String()[:2]
+		b.WriteString(helpStyle.Render(fmt.Sprintf("%*s", calendarCellWidth, name)))
+	}
+	b.WriteString("\n")
+
+	now := today()
+	offset := (int(month.Weekday()) - int(c.options.WeekStart) + 7) % 7
+	day := month.AddDate(0, 0, -offset)
+	for day.Before(month.AddDate(0, 1, 0)) {
+		var days, hours strings.Builder
+		for range 7 {
+			days.WriteString(c.dayCell(day, month, now))
+			hours.WriteString(c.hoursCell(day, month, now))
+			day = day.AddDate(0, 0, 1)
+		}
+		b.WriteString(days.String() + "\n")
+		if c.options.Loader != nil {
+			b.WriteString(hours.String() + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	if hours, ok := c.hours[c.cursor.Format(dates.Layout)]; ok {
+		b.WriteString(successStyle.Render(fmt.Sprintf("→ %s, %s logged", dates.Describe(c.cursor), duration.FormatHours(hours, c.options.HoursFormat))))
+	} else {
+		b.WriteString(successStyle.Render("→ " + dates.Describe(c.cursor)))
+	}
+	b.WriteString("\n")
+
+	if c.loading {
+		b.WriteString(helpStyle.Render("Loading logged hours..."))
+		b.WriteString("\n")
+	}
+	if c.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Could not load logged hours: %v", c.err)))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func (c calendar) dayCell(day, month, now time.Time) string {
+	cell := fmt.Sprintf("%*d", calendarCellWidth-1, day.Day())
+	switch {
+	case day.Equal(c.cursor):
+		return cell[:len(cell)-2] + calendarCursorStyle.Render(cell[len(cell)-2:]) + " "
+	case day.Month() != month.Month():
+		return calendarOtherMonthStyle.Render(cell) + " "
+	case day.Equal(now):
+		return calendarTodayStyle.Render(cell) + " "
+	}
+	return cell + " "
+}
+
+// hoursCell shows the hours logged on day, or a dash for past days of the
+// month where nothing was logged
+func (c calendar) hoursCell(day, month, now time.Time) string {
+	if day.Month() != month.Month() {
+		return strings.Repeat(" ", calendarCellWidth)
+	}
+
+	hours, ok := c.hours[day.Format(dates.Layout)]
+	switch {
+	case ok && hours > 0:
+		return successStyle.Render(fmt.Sprintf("%*s", calendarCellWidth-1, duration.FormatHours(hours, c.options.HoursFormat))) + " "
+	case day.Before(now) && c.loaded[month.Format("2006-01")] && !c.loading:
+		return errorStyle.Render(fmt.Sprintf("%*s", calendarCellWidth-1, "-")) + " "
+	}
+	return strings.Repeat(" ", calendarCellWidth)
+}
+
+// EntryHoursLoader totals the hours of the current user's time entries
+type EntryHoursLoader struct {
+	client *api.Client
+	mu     sync.Mutex
+	userId int64
+}
+
+func (l *EntryHoursLoader) Load(ctx context.Context, from, to time.Time) (map[string]float64, error) {
+	userId, err := l.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := api.TimeEntryQuery{
+		From:   from.Format(dates.Layout),
+		To:     to.Format(dates.Layout),
+		UserId: userId,
+	}
+
+	hours := map[string]float64{}
+	for entry, err := range l.client.Entries(ctx, query) {
+		if err != nil {
+			return nil, err
+		}
+		hours[entry.SpentDate] += entry.Hours
+	}
+	return hours, nil
+}
+
+// currentUser looks up the current user once, months may load concurrently
+func (l *EntryHoursLoader) currentUser(ctx context.Context) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.userId == 0 {
+		me, err := l.client.GetMe(ctx)
+		if err != nil {
+			return 0, err
+		}
+		l.userId = me.ID
+	}
+	return l.userId, nil
+}
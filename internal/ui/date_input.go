@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"harvest-cli/internal/dates"
 )
 
 // Styles for the date input
@@ -38,21 +40,32 @@ type DateInputModel struct {
 	submitted   bool
 	title       string
 	placeholder string
+	// preview is the date the current input resolves to, shown live
+	preview string
+	// calendar is the month grid used instead of typing in calendar mode
+	calendar     calendar
+	calendarMode bool
 }
 
+// openCalendarMsg switches a date input to calendar mode once it runs
+type openCalendarMsg struct{}
+
 // NewDateInput creates a new date input component
 func NewDateInput(title string) DateInputModel {
 	ti := textinput.New()
 	ti.Placeholder = "YYYY-MM-DD"
 	ti.Focus()
-	ti.CharLimit = 10
-	ti.Width = 20
+	ti.CharLimit = 32
+	ti.Width = 32
 
-	return DateInputModel{
+	m := DateInputModel{
 		textInput:   ti,
 		title:       title,
 		placeholder: "YYYY-MM-DD",
+		calendar:    newCalendar(context.Background(), CalendarOptions{WeekStart: time.Monday}),
 	}
+	m.updatePreview()
+	return m
 }
 
 // SetPlaceholder sets a custom placeholder for the input
@@ -61,8 +74,67 @@ func (m *DateInputModel) SetPlaceholder(placeholder string) {
 	m.textInput.Placeholder = placeholder
 }
 
+// SetValue pre-fills the input, e.g. with the current value when editing
+func (m *DateInputModel) SetValue(value string) {
+	m.textInput.SetValue(value)
+	m.updatePreview()
+}
+
+// Focus focuses the date input
+func (m *DateInputModel) Focus() {
+	m.textInput.Focus()
+}
+
+// Blur removes focus from the date input
+func (m *DateInputModel) Blur() {
+	m.textInput.Blur()
+}
+
+// reopen lets a submitted date be edited again, keeping its value, for
+// forms where fields are revisited
+func (m *DateInputModel) reopen() {
+	m.submitted = false
+	m.validDate = nil
+}
+
+// SetCalendar configures the calendar mode, loading the logged hours of
+// each month shown with options.Loader. The loader is cancelled with ctx.
+func (m *DateInputModel) SetCalendar(ctx context.Context, options CalendarOptions) {
+	m.calendar = newCalendar(ctx, options)
+}
+
+// openCalendar switches to calendar mode on the date typed so far, or today
+func (m *DateInputModel) openCalendar() tea.Cmd {
+	m.calendarMode = true
+	cursor := today()
+	if date, err := dates.Parse(m.textInput.Value(), time.Now()); err == nil {
+		cursor = date
+	}
+	return m.calendar.moveTo(cursor)
+}
+
+// updatePreview resolves the current input so the user can check it before
+// submitting
+func (m *DateInputModel) updatePreview() {
+	dateStr := strings.TrimSpace(m.textInput.Value())
+	if dateStr == "" {
+		m.preview = dates.Describe(time.Now()) + " (today)"
+		return
+	}
+
+	date, err := dates.Parse(dateStr, time.Now())
+	if err != nil {
+		m.preview = ""
+		return
+	}
+	m.preview = dates.Describe(date)
+}
+
 // Init initializes the date input component
 func (m DateInputModel) Init() tea.Cmd {
+	if m.calendar.options.Open {
+		return tea.Batch(textinput.Blink, func() tea.Msg { return openCalendarMsg{} })
+	}
 	return textinput.Blink
 }
 
@@ -71,8 +143,22 @@ func (m DateInputModel) Update(msg tea.Msg) (DateInputModel, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case openCalendarMsg:
+		return m, m.openCalendar()
+
+	case dayHoursLoadedMsg, dayHoursErrorMsg:
+		_, cmd = m.calendar.update(msg)
+		return m, cmd
+
 	case tea.KeyMsg:
+		if m.calendarMode {
+			return m.updateCalendar(msg)
+		}
+
 		switch msg.Type {
+		case tea.KeyDown:
+			return m, m.openCalendar()
+
 		case tea.KeyEnter:
 			// Validate the date when Enter is pressed
 			dateStr := strings.TrimSpace(m.textInput.Value())
@@ -84,10 +170,10 @@ func (m DateInputModel) Update(msg tea.Msg) (DateInputModel, tea.Cmd) {
 				// Use today's date if no input provided
 				parsedDate = time.Now()
 			} else {
-				// Try to parse the provided date
-				parsedDate, err = time.Parse("2006-01-02", dateStr)
+				// Try to resolve the provided date expression
+				parsedDate, err = dates.Parse(dateStr, time.Now())
 				if err != nil {
-					m.err = fmt.Errorf("invalid date format. Use YYYY-MM-DD")
+					m.err = err
 					return m, nil
 				}
 			}
@@ -109,6 +195,32 @@ func (m DateInputModel) Update(msg tea.Msg) (DateInputModel, tea.Cmd) {
 	}
 
 	m.textInput, cmd = m.textInput.Update(msg)
+	m.updatePreview()
+	return m, cmd
+}
+
+// updateCalendar handles the keys of calendar mode
+func (m DateInputModel) updateCalendar(msg tea.KeyMsg) (DateInputModel, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		// Don't quit here, let the parent handle it
+		return m, nil
+	case "i", "/":
+		// Back to typing, starting from the highlighted day
+		m.calendarMode = false
+		m.textInput.SetValue(m.calendar.cursor.Format(dates.Layout))
+		m.textInput.CursorEnd()
+		m.updatePreview()
+		return m, nil
+	}
+
+	picked, cmd := m.calendar.update(msg)
+	if picked {
+		date := m.calendar.cursor
+		m.validDate = &date
+		m.err = nil
+		m.submitted = true
+	}
 	return m, cmd
 }
 
@@ -121,12 +233,27 @@ func (m DateInputModel) View() string {
 		return b.String()
 	}
 
+	if m.calendarMode {
+		b.WriteString(m.calendar.view())
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("←↑↓→ move, PgUp/PgDn change month, t today, i type a date"))
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("Press Enter to pick the highlighted day"))
+		return b.String()
+	}
+
 	// Input prompt
 	b.WriteString("Enter a date:")
 	b.WriteString("\n\n")
 
 	// Text input
 	b.WriteString(inputStyle.Render(m.textInput.View()))
+	b.WriteString("\n")
+
+	// Live preview of the resolved date
+	if m.preview != "" {
+		b.WriteString(successStyle.Render("→ " + m.preview))
+	}
 	b.WriteString("\n\n")
 
 	// Error message if any
@@ -136,9 +263,9 @@ func (m DateInputModel) View() string {
 	}
 
 	// Help text
-	b.WriteString(helpStyle.Render(fmt.Sprintf("Format: %s (e.g., 2024-12-25)", m.placeholder)))
+	b.WriteString(helpStyle.Render(fmt.Sprintf("Format: %s, today, yesterday, mon, last friday or -2d", m.placeholder)))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Press Enter to submit (empty = today's date)"))
+	b.WriteString(helpStyle.Render("Press Enter to submit (empty = today's date), ↓ to pick on a calendar"))
 
 	return b.String()
 }
@@ -170,77 +297,7 @@ func (m *DateInputModel) Reset() {
 	m.err = nil
 	m.validDate = nil
 	m.submitted = false
+	m.calendarMode = false
 	m.textInput.Focus()
-}
-
-// TextInputDate is a convenience function to get a date input from the user in ISO 8601 format
-func TextInputDate(title string) (string, error) {
-	model := NewDateInput(title)
-	
-	program := tea.NewProgram(dateInputWrapper{model})
-	finalModel, err := program.Run()
-	if err != nil {
-		return "", err
-	}
-
-	if wrapper, ok := finalModel.(dateInputWrapper); ok {
-		return wrapper.model.GetDateISO8601(), nil
-	}
-	
-	return "", fmt.Errorf("failed to get date input")
-}
-
-// TextInputDateAsTime is a convenience function to get a date input as *time.Time
-func TextInputDateAsTime(title string) (*time.Time, error) {
-	model := NewDateInput(title)
-	
-	program := tea.NewProgram(dateInputWrapper{model})
-	finalModel, err := program.Run()
-	if err != nil {
-		return nil, err
-	}
-
-	if wrapper, ok := finalModel.(dateInputWrapper); ok {
-		return wrapper.model.GetDate(), nil
-	}
-	
-	return nil, fmt.Errorf("failed to get date input")
-}
-
-// dateInputWrapper wraps the DateInputModel to handle quit behavior
-type dateInputWrapper struct {
-	model DateInputModel
-}
-
-func (w dateInputWrapper) Init() tea.Cmd {
-	return w.model.Init()
-}
-
-func (w dateInputWrapper) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyEsc:
-			return w, tea.Quit
-		case tea.KeyEnter:
-			newModel, cmd := w.model.Update(msg)
-			w.model = newModel
-			if w.model.IsSubmitted() {
-				return w, tea.Quit
-			}
-			return w, cmd
-		}
-	}
-
-	newModel, cmd := w.model.Update(msg)
-	w.model = newModel
-	return w, cmd
-}
-
-func (w dateInputWrapper) View() string {
-	view := w.model.View()
-	if !w.model.IsSubmitted() {
-		view += "\n" + helpStyle.Render("Press Esc to cancel")
-	}
-	return view
+	m.updatePreview()
 }
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Editor returns the user's preferred editor command from $VISUAL or
+// $EDITOR, or an empty string when neither is set
+func Editor() string {
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+	return os.Getenv("EDITOR")
+}
+
+// EditInEditor opens value in the user's editor and returns the saved text.
+// The help lines are appended as comments; every line starting with # is
+// dropped from the result, like git does for commit messages.
+func EditInEditor(value string, help []string) (string, error) {
+	command, path, err := editorCommand(value, help)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(path)
+
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("editor %q failed: %w", Editor(), err)
+	}
+
+	return readEdited(path)
+}
+
+// editorCommand writes value and the help comments to a temporary file and
+// returns the command opening it in the user's editor. The caller removes
+// the file at path.
+func editorCommand(value string, help []string) (*exec.Cmd, string, error) {
+	editor := Editor()
+	if editor == "" {
+		return nil, "", fmt.Errorf("no editor configured, set $EDITOR")
+	}
+
+	file, err := os.CreateTemp("", "harvest-*.txt")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString(value)
+	b.WriteString("\n")
+	for _, line := range help {
+		b.WriteString("# " + line + "\n")
+	}
+	if _, err := file.WriteString(b.String()); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return nil, "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	// The editor may come with arguments, e.g. "code --wait"
+	args := strings.Fields(editor)
+	return exec.Command(args[0], append(args[1:], file.Name())...), file.Name(), nil
+}
+
+// readEdited reads the file saved by the editor, without comment lines
+func readEdited(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read temporary file: %w", err)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		if !strings.HasPrefix(scanner.Text(), "#") {
+			lines = append(lines, scanner.Text())
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
@@ -0,0 +1,687 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"harvest-cli/internal/api"
+	"harvest-cli/internal/dates"
+	"harvest-cli/internal/duration"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles for the entry form
+var (
+	formLabelStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241")).
+			Width(10)
+
+	formFocusedLabelStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("205")).
+				Bold(true).
+				Width(10)
+
+	formPaneStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("62")).
+			Padding(0, 1)
+)
+
+// EntryFormValues holds the fields of a time entry filled in the entry form
+type EntryFormValues struct {
+	ProjectId int64
+	TaskId    int64
+	// Date is in YYYY-MM-DD format, today when left empty
+	Date  string
+	Hours float64
+	Notes string
+}
+
+// EntryFormOptions configures the entry form
+type EntryFormOptions struct {
+	Title string
+	// Values pre-fills the form, zero values leave the field empty
+	Values EntryFormValues
+	// Calendar configures the calendar of the date field, the hours logged
+	// by the current user are shown when no loader is set
+	Calendar CalendarOptions
+	// NotesHelp is shown as comments when the notes are written in $EDITOR
+	NotesHelp []string
+	// SubmitLabel describes what Enter does on the summary, e.g. "create the entry"
+	SubmitLabel string
+	// Assignments lists the projects and tasks to choose from, the client
+	// by default
+	Assignments AssignmentLister
+}
+
+type formField int
+
+const (
+	formProject formField = iota
+	formTask
+	formDate
+	formDuration
+	formNotes
+	formSummary
+	formFieldCount
+)
+
+var formFieldLabels = [formFieldCount]string{"Project", "Task", "Date", "Duration", "Notes", "Summary"}
+
+// formFieldMsg delivers a message to the component of a single field. gen
+// drops the messages of a task selector replaced since by another project.
+type formFieldMsg struct {
+	field formField
+	gen   int
+	msg   tea.Msg
+}
+
+type notesEditedMsg struct {
+	notes string
+	err   error
+}
+
+// EntryFormModel edits every field of a time entry on a single screen,
+// reusing the selector, date and text inputs as its fields
+type EntryFormModel struct {
+	ctx     context.Context
+	client  *api.Client
+	options EntryFormOptions
+	values  EntryFormValues
+	focus   formField
+	errs    [formFieldCount]error
+
+	projects *selectorModel[ProjectSelectable]
+	tasks    *selectorModel[TaskSelectable]
+	taskGen  int
+	date     DateInputModel
+	duration TextInputModel
+	notes    TextAreaModel
+
+	projectName string
+	clientName  string
+	taskName    string
+
+	submitted bool
+	cancelled bool
+}
+
+// NewEntryForm creates a new entry form
+func NewEntryForm(ctx context.Context, client *api.Client, options EntryFormOptions) *EntryFormModel {
+	if options.Title == "" {
+		options.Title = "Time entry"
+	}
+	if options.SubmitLabel == "" {
+		options.SubmitLabel = "save the entry"
+	}
+	if options.Calendar.HoursFormat == "" {
+		options.Calendar.HoursFormat = duration.Decimal
+	}
+	if options.Calendar.Loader == nil {
+		options.Calendar.Loader = &EntryHoursLoader{client: client}
+	}
+	if options.Assignments == nil {
+		options.Assignments = client
+	}
+
+	m := &EntryFormModel{
+		ctx:     ctx,
+		client:  client,
+		options: options,
+		values:  options.Values,
+	}
+
+	m.projects = NewSelector(ctx, &ProjectLoader{client: options.Assignments}, SelectorConfig{
+		Title:      "Select a Project",
+		EmptyMsg:   "No projects found.",
+		LoadingMsg: "Loading projects...",
+		InitialID:  formatID(m.values.ProjectId),
+	})
+	m.projects.embedded = true
+	if m.values.ProjectId != 0 {
+		m.newTaskSelector()
+	}
+
+	m.date = NewDateInput("Date")
+	m.date.SetValue(m.values.Date)
+	m.date.SetCalendar(ctx, options.Calendar)
+	// The date always has a value, today when none was given
+	m.commit(formDate)
+
+	hours := ""
+	if m.values.Hours > 0 {
+		hours = duration.FormatHours(m.values.Hours, options.Calendar.HoursFormat)
+	}
+	m.duration = NewTextInput(TextInputOptions{
+		Prompt:       "What was the duration? (ex. 1h30m / 1:30 / 1.5 / 90)",
+		Required:     true,
+		DefaultValue: hours,
+		ValidateFunc: func(value string) error {
+			_, err := duration.Parse(value)
+			return err
+		},
+	})
+	m.notes = NewTextArea(TextAreaOptions{
+		Prompt:       "What did you work on?",
+		CharLimit:    api.MaxNotesLength,
+		Width:        60,
+		Height:       4,
+		DefaultValue: m.values.Notes,
+		ValidateFunc: api.ValidateNotes,
+	})
+
+	// Start on the first field left to fill
+	switch {
+	case m.values.ProjectId == 0:
+		m.setFocus(formProject)
+	case m.values.TaskId == 0:
+		m.setFocus(formTask)
+	case m.values.Hours == 0:
+		m.setFocus(formDuration)
+	case m.values.Notes == "":
+		m.setFocus(formNotes)
+	default:
+		m.setFocus(formSummary)
+	}
+
+	return m
+}
+
+// newTaskSelector replaces the task selector with one for the current
+// project, cancelling the loading of the previous one
+func (m *EntryFormModel) newTaskSelector() tea.Cmd {
+	if m.tasks != nil {
+		m.tasks.cancel()
+	}
+	m.taskGen++
+
+	m.tasks = NewSelector(m.ctx, &TaskLoader{client: m.options.Assignments, projectId: m.values.ProjectId}, SelectorConfig{
+		Title:      "Select a Task",
+		EmptyMsg:   "No tasks found.",
+		LoadingMsg: "Loading tasks...",
+		InitialID:  formatID(m.values.TaskId),
+	})
+	m.tasks.embedded = true
+	return m.route(formTask, m.tasks.Init())
+}
+
+// route tags the messages produced by cmd with the field they belong to
+func (m *EntryFormModel) route(field formField, cmd tea.Cmd) tea.Cmd {
+	gen := 0
+	if field == formTask {
+		gen = m.taskGen
+	}
+	return routeCmd(field, gen, cmd)
+}
+
+func routeCmd(field formField, gen int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			cmds := make([]tea.Cmd, len(batch))
+			for i, c := range batch {
+				cmds[i] = routeCmd(field, gen, c)
+			}
+			return tea.BatchMsg(cmds)
+		}
+		return formFieldMsg{field: field, gen: gen, msg: msg}
+	}
+}
+
+// close stops the loaders still running
+func (m *EntryFormModel) close() {
+	m.projects.cancel()
+	if m.tasks != nil {
+		m.tasks.cancel()
+	}
+}
+
+func (m *EntryFormModel) Init() tea.Cmd {
+	cmds := []tea.Cmd{
+		m.route(formProject, m.projects.Init()),
+		m.route(formDate, m.date.Init()),
+		m.route(formDuration, m.duration.Init()),
+		m.route(formNotes, m.notes.Init()),
+	}
+	if m.tasks != nil {
+		cmds = append(cmds, m.route(formTask, m.tasks.Init()))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *EntryFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case formFieldMsg:
+		return m, m.updateField(msg.field, msg.gen, msg.msg)
+
+	case notesEditedMsg:
+		if msg.err != nil {
+			m.errs[formNotes] = msg.err
+			return m, nil
+		}
+		m.values.Notes = msg.notes
+		m.notes.SetValue(msg.notes)
+		m.errs[formNotes] = api.ValidateNotes(msg.notes)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			m.cancelled = true
+			m.close()
+			return m, tea.Quit
+		case "esc":
+			// Esc clears the filter of a selector before leaving the form
+			if !m.filtering() {
+				m.cancelled = true
+				m.close()
+				return m, tea.Quit
+			}
+		case "tab":
+			m.commit(m.focus)
+			m.setFocus((m.focus + 1) % formFieldCount)
+			return m, nil
+		case "shift+tab":
+			m.commit(m.focus)
+			m.setFocus((m.focus + formFieldCount - 1) % formFieldCount)
+			return m, nil
+		case "ctrl+e":
+			if m.focus == formNotes && Editor() != "" {
+				return m, m.editNotes()
+			}
+		case "enter":
+			if m.focus == formSummary {
+				return m, m.submit()
+			}
+		}
+		return m, m.updateFocused(msg)
+	}
+
+	return m, nil
+}
+
+// updateField hands a routed message to the component of field
+func (m *EntryFormModel) updateField(field formField, gen int, msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	switch field {
+	case formProject:
+		_, cmd = m.projects.Update(msg)
+		m.resolveNames()
+	case formTask:
+		if m.tasks == nil || gen != m.taskGen {
+			return nil
+		}
+		_, cmd = m.tasks.Update(msg)
+		m.resolveNames()
+	case formDate:
+		m.date, cmd = m.date.Update(msg)
+	case formDuration:
+		m.duration, cmd = m.duration.Update(msg)
+	case formNotes:
+		m.notes, cmd = m.notes.Update(msg)
+	}
+	return m.route(field, cmd)
+}
+
+// updateFocused hands a key to the focused field, moving to the next
+// field once its value is picked or submitted
+func (m *EntryFormModel) updateFocused(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	switch m.focus {
+	case formProject:
+		previous := m.projects.selected
+		_, cmd = m.projects.Update(msg)
+		if selected := m.projects.selected; selected != previous && selected != nil {
+			cmd = tea.Batch(m.route(formProject, cmd), m.selectProject(*selected))
+			m.setFocus(formTask)
+			return cmd
+		}
+	case formTask:
+		if m.tasks == nil {
+			return nil
+		}
+		previous := m.tasks.selected
+		_, cmd = m.tasks.Update(msg)
+		if selected := m.tasks.selected; selected != previous && selected != nil {
+			m.values.TaskId = selected.Task.ID
+			m.taskName = selected.Task.Name
+			m.errs[formTask] = nil
+			m.setFocus(formDate)
+		}
+	case formDate:
+		m.date, cmd = m.date.Update(msg)
+		if m.collect(formDate) {
+			m.setFocus(formDuration)
+		}
+	case formDuration:
+		m.duration, cmd = m.duration.Update(msg)
+		if m.collect(formDuration) {
+			m.setFocus(formNotes)
+		}
+	case formNotes:
+		m.notes, cmd = m.notes.Update(msg)
+		if m.collect(formNotes) {
+			m.setFocus(formSummary)
+		}
+	}
+	return m.route(m.focus, cmd)
+}
+
+// selectProject keeps the picked project, loading its tasks when it
+// changed
+func (m *EntryFormModel) selectProject(selected ProjectSelectable) tea.Cmd {
+	m.errs[formProject] = nil
+	m.projectName = selected.Project.Name
+	m.clientName = selected.Client.Name
+	if selected.Project.ID == m.values.ProjectId && m.tasks != nil {
+		return nil
+	}
+
+	m.values.ProjectId = selected.Project.ID
+	m.values.TaskId = 0
+	m.taskName = ""
+	return m.newTaskSelector()
+}
+
+// resolveNames looks up the names of pre-filled IDs once their selector
+// has loaded
+func (m *EntryFormModel) resolveNames() {
+	if m.projectName == "" && m.values.ProjectId != 0 {
+		for _, item := range m.projects.items {
+			if item.Project.ID == m.values.ProjectId {
+				m.projectName = item.Project.Name
+				m.clientName = item.Client.Name
+			}
+		}
+	}
+	if m.taskName == "" && m.values.TaskId != 0 && m.tasks != nil {
+		for _, item := range m.tasks.items {
+			if item.Task.ID == m.values.TaskId {
+				m.taskName = item.Task.Name
+			}
+		}
+	}
+}
+
+// commit validates the value typed in a field when leaving it with tab
+func (m *EntryFormModel) commit(field formField) {
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	switch field {
+	case formDate:
+		m.date, _ = m.date.Update(enter)
+	case formDuration:
+		m.duration, _ = m.duration.Update(enter)
+	case formNotes:
+		// Enter starts a new line in the notes
+		m.notes, _ = m.notes.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	default:
+		return
+	}
+	m.collect(field)
+}
+
+// collect keeps the value of a text field once it was submitted, leaving
+// the field open for later changes. It reports whether the value was valid.
+func (m *EntryFormModel) collect(field formField) bool {
+	switch field {
+	case formDate:
+		if !m.date.IsSubmitted() {
+			m.errs[formDate] = m.date.err
+			return false
+		}
+		m.values.Date = m.date.GetDateISO8601()
+		m.date.reopen()
+	case formDuration:
+		if !m.duration.IsSubmitted() {
+			m.errs[formDuration] = m.duration.err
+			return false
+		}
+		m.values.Hours, _ = duration.Parse(m.duration.GetValue())
+		m.duration.reopen()
+	case formNotes:
+		if !m.notes.IsSubmitted() {
+			m.errs[formNotes] = m.notes.err
+			return false
+		}
+		m.values.Notes = m.notes.GetValue()
+		m.notes.reopen()
+	}
+	m.errs[field] = nil
+	return true
+}
+
+// setFocus moves to field, only the focused text input shows a cursor
+func (m *EntryFormModel) setFocus(field formField) {
+	m.focus = field
+	m.date.Blur()
+	m.duration.Blur()
+	m.notes.Blur()
+
+	switch field {
+	case formDate:
+		m.date.Focus()
+	case formDuration:
+		m.duration.Focus()
+	case formNotes:
+		m.notes.Focus()
+	}
+}
+
+// filtering reports whether the focused selector is being filtered
+func (m *EntryFormModel) filtering() bool {
+	switch {
+	case m.focus == formProject && !m.projects.loading:
+		return m.projects.list.FilterState() == list.Filtering
+	case m.focus == formTask && m.tasks != nil && !m.tasks.loading:
+		return m.tasks.list.FilterState() == list.Filtering
+	}
+	return false
+}
+
+// editNotes suspends the form to write the notes in $EDITOR
+func (m *EntryFormModel) editNotes() tea.Cmd {
+	command, path, err := editorCommand(m.notes.textArea.Value(), m.options.NotesHelp)
+	if err != nil {
+		m.errs[formNotes] = err
+		return nil
+	}
+
+	return tea.ExecProcess(command, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return notesEditedMsg{err: fmt.Errorf("editor %q failed: %w", Editor(), err)}
+		}
+		notes, err := readEdited(path)
+		return notesEditedMsg{notes: notes, err: err}
+	})
+}
+
+// validate returns the errors of every field, including the required
+// fields left empty, and the first field to fix
+func (m *EntryFormModel) validate() ([formFieldCount]error, formField, bool) {
+	errs := m.errs
+	if m.values.ProjectId == 0 {
+		errs[formProject] = fmt.Errorf("choose a project")
+	}
+	if m.values.TaskId == 0 {
+		errs[formTask] = fmt.Errorf("choose a task")
+	}
+	if m.values.Hours == 0 && errs[formDuration] == nil {
+		errs[formDuration] = fmt.Errorf("enter a duration")
+	}
+
+	for field := range formSummary {
+		if errs[field] != nil {
+			return errs, field, false
+		}
+	}
+	return errs, formSummary, true
+}
+
+// submit closes the form when every field is valid, otherwise it marks the
+// invalid fields and moves to the first one
+func (m *EntryFormModel) submit() tea.Cmd {
+	errs, field, ok := m.validate()
+	if !ok {
+		m.errs = errs
+		m.setFocus(field)
+		return nil
+	}
+
+	m.submitted = true
+	m.close()
+	return tea.Quit
+}
+
+func (m *EntryFormModel) View() string {
+	if m.submitted || m.cancelled {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(m.options.Title))
+	b.WriteString("\n\n")
+
+	for field := range formSummary {
+		label := formLabelStyle.Render(formFieldLabels[field])
+		cursor := "  "
+		if field == m.focus {
+			label = formFocusedLabelStyle.Render(formFieldLabels[field])
+			cursor = inputStyle.Render("> ")
+		}
+
+		b.WriteString(cursor + label + m.fieldValue(field))
+		if m.errs[field] != nil {
+			b.WriteString(errorStyle.Render("  ✗ " + m.errs[field].Error()))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.focusedView())
+	b.WriteString("\n")
+
+	help := "tab/shift+tab move between fields, enter confirm the field, esc cancel"
+	if m.focus == formNotes {
+		help = "tab/shift+tab move between fields, ctrl+s confirm the notes, enter new line, esc cancel"
+		if Editor() != "" {
+			help += ", ctrl+e open $EDITOR"
+		}
+	}
+	b.WriteString(helpStyle.Render(help))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// fieldValue is the one-line summary of a field shown in the field list
+func (m *EntryFormModel) fieldValue(field formField) string {
+	value := ""
+	switch field {
+	case formProject:
+		value = m.projectName
+		if value == "" && m.values.ProjectId != 0 {
+			value = formatID(m.values.ProjectId)
+		}
+		if m.clientName != "" {
+			value += helpStyle.Render(" (" + m.clientName + ")")
+		}
+	case formTask:
+		value = m.taskName
+		if value == "" && m.values.TaskId != 0 {
+			value = formatID(m.values.TaskId)
+		}
+	case formDate:
+		if date, err := time.ParseInLocation(dates.Layout, m.values.Date, time.Local); err == nil {
+			value = dates.Describe(date)
+		}
+	case formDuration:
+		if m.values.Hours > 0 {
+			value = duration.FormatHours(m.values.Hours, m.options.Calendar.HoursFormat)
+		}
+	case formNotes:
+		value, _, _ = strings.Cut(m.values.Notes, "\n")
+		// Cut whole characters so that accented notes stay valid UTF-8
+		if runes := []rune(value); len(runes) > 50 {
+			value = string(runes[:47]) + "..."
+		}
+	}
+
+	if value == "" {
+		return helpStyle.Render("-")
+	}
+	return value
+}
+
+// focusedView renders the component of the focused field, or the summary
+func (m *EntryFormModel) focusedView() string {
+	switch m.focus {
+	case formProject:
+		return m.projects.View()
+	case formTask:
+		if m.tasks == nil {
+			return helpStyle.Render("Choose a project first.") + "\n"
+		}
+		return m.tasks.View()
+	case formDate:
+		return m.date.View() + "\n"
+	case formDuration:
+		return m.duration.View()
+	case formNotes:
+		return m.notes.View()
+	}
+	return m.summaryView()
+}
+
+// summaryView shows the whole entry before it is submitted
+func (m *EntryFormModel) summaryView() string {
+	var b strings.Builder
+	for field := range formNotes {
+		b.WriteString(formLabelStyle.Render(formFieldLabels[field]) + m.fieldValue(field) + "\n")
+	}
+	b.WriteString(formLabelStyle.Render(formFieldLabels[formNotes]))
+	if m.values.Notes == "" {
+		b.WriteString(helpStyle.Render("-"))
+	} else {
+		b.WriteString(strings.ReplaceAll(m.values.Notes, "\n", "\n"+strings.Repeat(" ", 10)))
+	}
+
+	view := formPaneStyle.Render(b.String()) + "\n\n"
+	if _, _, ok := m.validate(); ok {
+		view += successStyle.Render("Press Enter to "+m.options.SubmitLabel) + "\n"
+	} else {
+		view += errorStyle.Render("Fix the fields marked ✗ first") + "\n"
+	}
+	return view
+}
+
+// EntryForm asks for every field of a time entry on a single screen and
+// returns the submitted values, or nil when the user cancelled
+func EntryForm(ctx context.Context, client *api.Client, options EntryFormOptions) (*EntryFormValues, error) {
+	model := NewEntryForm(ctx, client, options)
+	defer model.close()
+
+	program := tea.NewProgram(model, tea.WithContext(ctx))
+	finalModel, err := program.Run()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	if form, ok := finalModel.(*EntryFormModel); ok && form.submitted {
+		values := form.values
+		return &values, nil
+	}
+	return nil, nil
+}
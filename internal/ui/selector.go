@@ -1,10 +1,14 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"harvest-cli/internal/api"
+	"harvest-cli/internal/dates"
+	"harvest-cli/internal/resolve"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -19,7 +23,20 @@ type Selectable interface {
 }
 
 type DataLoader[T Selectable] interface {
-	Load() ([]T, error)
+	Load(ctx context.Context) ([]T, error)
+}
+
+// filterable is implemented by the Selectables filtered on more than
+// their title
+type filterable interface {
+	FilterValue() string
+}
+
+// SliceLoader loads items that were already fetched
+type SliceLoader[T Selectable] []T
+
+func (sl SliceLoader[T]) Load(ctx context.Context) ([]T, error) {
+	return sl, nil
 }
 
 // Generic list item wrapper
@@ -27,7 +44,12 @@ type selectableItem[T Selectable] struct {
 	item T
 }
 
-func (i selectableItem[T]) FilterValue() string { return i.item.GetTitle() }
+func (i selectableItem[T]) FilterValue() string {
+	if f, ok := any(i.item).(filterable); ok {
+		return f.FilterValue()
+	}
+	return i.item.GetTitle()
+}
 func (i selectableItem[T]) Title() string       { return i.item.GetTitle() }
 func (i selectableItem[T]) Description() string { return i.item.GetDescription() }
 
@@ -44,6 +66,13 @@ type selectorModel[T Selectable] struct {
 	title      string
 	emptyMsg   string
 	loadingMsg string
+	initialID  string
+	filter     string
+	ctx        context.Context
+	cancel     context.CancelFunc
+	// embedded selectors are part of a larger model, like EntryFormModel,
+	// and leave quitting to it
+	embedded bool
 }
 
 type itemsLoadedMsg[T Selectable] []T
@@ -56,9 +85,13 @@ type SelectorConfig struct {
 	LoadingMsg string
 	Width      int
 	Height     int
+	// InitialID is the ID of the item to highlight once loaded
+	InitialID string
+	// Filter pre-fills the filter of the list, e.g. with an ambiguous name
+	Filter string
 }
 
-func NewSelector[T Selectable](loader DataLoader[T], config SelectorConfig) *selectorModel[T] {
+func NewSelector[T Selectable](ctx context.Context, loader DataLoader[T], config SelectorConfig) *selectorModel[T] {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
@@ -77,9 +110,12 @@ func NewSelector[T Selectable](loader DataLoader[T], config SelectorConfig) *sel
 		config.Width = 80
 	}
 	if config.Height == 0 {
-		config.Height = 28 
+		config.Height = 28
 	}
 
+	// The loader is cancelled when the user quits before it finishes
+	ctx, cancel := context.WithCancel(ctx)
+
 	return &selectorModel[T]{
 		loading:    true,
 		spinner:    s,
@@ -87,6 +123,10 @@ func NewSelector[T Selectable](loader DataLoader[T], config SelectorConfig) *sel
 		title:      config.Title,
 		emptyMsg:   config.EmptyMsg,
 		loadingMsg: config.LoadingMsg,
+		initialID:  config.InitialID,
+		filter:     config.Filter,
+		ctx:        ctx,
+		cancel:     cancel,
 	}
 }
 
@@ -95,7 +135,7 @@ func (m *selectorModel[T]) Init() tea.Cmd {
 }
 
 func (m *selectorModel[T]) loadItems() tea.Msg {
-	items, err := m.loader.Load()
+	items, err := m.loader.Load(m.ctx)
 	if err != nil {
 		return itemsErrorMsg(err)
 	}
@@ -107,12 +147,19 @@ func (m *selectorModel[T]) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q":
+			if m.embedded {
+				break
+			}
 			m.quitting = true
+			m.cancel()
 			return m, tea.Quit
 		case "enter":
 			if !m.loading && len(m.items) > 0 {
 				if selectedItem, ok := m.list.SelectedItem().(selectableItem[T]); ok {
 					m.selected = &selectedItem.item
+					if m.embedded {
+						return m, nil
+					}
 					m.quitting = true
 					return m, tea.Quit
 				}
@@ -120,6 +167,7 @@ func (m *selectorModel[T]) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case itemsLoadedMsg[T]:
+		m.cancel()
 		m.loading = false
 		m.items = []T(msg)
 
@@ -134,15 +182,32 @@ func (m *selectorModel[T]) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		l.Title = m.title
 		l.SetShowStatusBar(false)
 		l.SetFilteringEnabled(true)
+		if m.embedded {
+			// Leaving is up to the model embedding the selector
+			l.KeyMap.Quit.SetEnabled(false)
+			l.KeyMap.ForceQuit.SetEnabled(false)
+		}
 		l.Styles.Title = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("62")).
 			Bold(true).
 			Padding(0, 0, 1, 2)
 
+		for i, item := range m.items {
+			if m.initialID != "" && item.GetID() == m.initialID {
+				l.Select(i)
+				break
+			}
+		}
+
+		if m.filter != "" {
+			l.SetFilterText(m.filter)
+		}
+
 		m.list = l
 		return m, nil
 
 	case itemsErrorMsg:
+		m.cancel()
 		m.loading = false
 		m.err = error(msg)
 		return m, nil
@@ -181,15 +246,24 @@ func (m *selectorModel[T]) View() string {
 		return m.emptyMsg + "\n"
 	}
 
+	if m.embedded {
+		return m.list.View() + "\n"
+	}
+
 	return "\n" + m.list.View() + "\n\nPress Enter to select, q/esc to quit\n"
 }
 
-func RunSelector[T Selectable](loader DataLoader[T], config SelectorConfig) (*T, error) {
-	model := NewSelector(loader, config)
-	p := tea.NewProgram(model)
+func RunSelector[T Selectable](ctx context.Context, loader DataLoader[T], config SelectorConfig) (*T, error) {
+	model := NewSelector(ctx, loader, config)
+	defer model.cancel()
+
+	p := tea.NewProgram(model, tea.WithContext(ctx))
 
 	finalModel, err := p.Run()
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return nil, err
 	}
 
@@ -207,19 +281,23 @@ func RunSelector[T Selectable](loader DataLoader[T], config SelectorConfig) (*T,
 
 // Entry implementation of Selectable interface
 type EntrySelectable struct {
-	*api.Entry
+	*api.TimeEntry
 }
 
 func (e EntrySelectable) GetID() string {
-	return e.Entry.ID
+	return strconv.FormatInt(e.TimeEntry.ID, 10)
 }
 
 func (e EntrySelectable) GetTitle() string {
-	return e.Entry.Title
+	return fmt.Sprintf("%s | %s - %s", e.TimeEntry.SpentDate, e.TimeEntry.Project.Name, e.TimeEntry.Task.Name)
 }
 
 func (e EntrySelectable) GetDescription() string {
-	return fmt.Sprintf("ID: %s | Status: %s | Private: %t", e.Entry.ID, e.Entry.Status, e.Entry.Private)
+	notes := ""
+	if e.TimeEntry.Notes != nil {
+		notes = *e.TimeEntry.Notes
+	}
+	return fmt.Sprintf("ID: %d | Hours: %.2f | %s", e.TimeEntry.ID, e.TimeEntry.Hours, notes)
 }
 
 // Task implementation of Selectable interface
@@ -228,7 +306,7 @@ type TaskSelectable struct {
 }
 
 func (t TaskSelectable) GetID() string {
-	return string(t.TaskAssignment.Task.ID)
+	return strconv.FormatInt(t.TaskAssignment.Task.ID, 10)
 }
 
 func (t TaskSelectable) GetTitle() string {
@@ -236,7 +314,7 @@ func (t TaskSelectable) GetTitle() string {
 }
 
 func (t TaskSelectable) GetDescription() string {
-	return fmt.Sprintf("ID: %s | Project: %s", t.Task.ID)
+	return fmt.Sprintf("ID: %d | Billable: %t", t.Task.ID, t.TaskAssignment.Billable)
 }
 
 type ProjectSelectable struct {
@@ -252,6 +330,11 @@ func (p ProjectSelectable) GetTitle() string {
 	return p.ProjectAssignment.Project.Name
 }
 
+// FilterValue lets the list be filtered on the client and code as well
+func (p ProjectSelectable) FilterValue() string {
+	return resolve.Projects.Label(p.ProjectAssignment)
+}
+
 func (p ProjectSelectable) GetDescription() string {
 	return fmt.Sprintf("ID: %s | Client: %s", strconv.FormatInt(p.ProjectAssignment.Project.ID, 10), p.ProjectAssignment.Client.Name)
 }
@@ -259,36 +342,59 @@ func (p ProjectSelectable) GetDescription() string {
 // Entry loader implementation
 type EntryLoader struct {
 	client *api.Client
-	params api.ListParams
+	query  api.TimeEntryQuery
 }
 
-func (el *EntryLoader) Load() ([]EntrySelectable, error) {
-	entries, err := el.client.ListEntries(el.params)
+func (el *EntryLoader) Load(ctx context.Context) ([]EntrySelectable, error) {
+	// Only the user's own entries, which managers could otherwise edit by
+	// mistake
+	me, err := el.client.GetMe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := el.query
+	query.UserId = me.ID
+
+	entries, err := el.client.ListEntries(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
 	selectableEntries := make([]EntrySelectable, len(entries))
 	for i, entry := range entries {
-		selectableEntries[i] = EntrySelectable{Entry: entry}
+		selectableEntries[i] = EntrySelectable{TimeEntry: entry}
 	}
 
 	return selectableEntries, nil
 }
 
+// AssignmentLister lists the current user's project assignments, e.g.
+// *api.Client or the on-disk cache of internal/cache
+type AssignmentLister interface {
+	ListAssignedProjects(ctx context.Context, params api.ListParams) ([]*api.ProjectAssignment, error)
+}
+
 // Task loader implementation
 type TaskLoader struct {
-	client    *api.Client
+	client    AssignmentLister
 	params    api.ListParams
 	projectId int64
 }
 
-func (tl *TaskLoader) Load() ([]TaskSelectable, error) {
-	tasks, err := tl.client.ListTasks(tl.projectId, tl.params)
+func (tl *TaskLoader) Load(ctx context.Context) ([]TaskSelectable, error) {
+	projects, err := tl.client.ListAssignedProjects(ctx, tl.params)
 	if err != nil {
 		return nil, err
 	}
 
+	var tasks []*api.TaskAssignment
+	for _, project := range projects {
+		if project.Project.ID == tl.projectId {
+			tasks = project.TaskAssignments
+			break
+		}
+	}
+
 	selectableTasks := make([]TaskSelectable, len(tasks))
 	for i, task := range tasks {
 		selectableTasks[i] = TaskSelectable{TaskAssignment: task}
@@ -299,12 +405,12 @@ func (tl *TaskLoader) Load() ([]TaskSelectable, error) {
 
 // Project loader implementation
 type ProjectLoader struct {
-	client *api.Client
+	client AssignmentLister
 	params api.ListParams
 }
 
-func (pl *ProjectLoader) Load() ([]ProjectSelectable, error) {
-	projects, err := pl.client.ListAssignedProjects(pl.params)
+func (pl *ProjectLoader) Load(ctx context.Context) ([]ProjectSelectable, error) {
+	projects, err := pl.client.ListAssignedProjects(ctx, pl.params)
 	if err != nil {
 		return nil, err
 	}
@@ -317,24 +423,27 @@ func (pl *ProjectLoader) Load() ([]ProjectSelectable, error) {
 	return selectableProjects, nil
 }
 
-// Helper function to build list parameters
-func buildListParams() api.ListParams {
-	// Return the appropriate api.ListParams struct
-	// You'll need to adjust this based on your actual api.ListParams structure
-	return api.ListParams{
-		// Add fields based on your api.ListParams struct
-		// Examples (adjust field names to match your API):
-		// Limit:  100,
-		// Status: "",
-		// Page:   1,
+// formatID formats an ID for SelectorConfig.InitialID, zero meaning none
+func formatID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+// recentEntriesQuery limits the entry selector to the last 30 days, the
+// loader adding the current user
+func recentEntriesQuery() api.TimeEntryQuery {
+	return api.TimeEntryQuery{
+		From: time.Now().AddDate(0, 0, -30).Format(dates.Layout),
 	}
 }
 
 // SelectEntryInteractively allows interactive selection of an entry
-func SelectEntryInteractively(client *api.Client) (*api.Entry, error) {
+func SelectEntryInteractively(ctx context.Context, client *api.Client) (*api.TimeEntry, error) {
 	loader := &EntryLoader{
 		client: client,
-		params: buildListParams(),
+		query:  recentEntriesQuery(),
 	}
 	config := SelectorConfig{
 		Title:      "Select an Entry",
@@ -342,23 +451,26 @@ func SelectEntryInteractively(client *api.Client) (*api.Entry, error) {
 		LoadingMsg: "Loading entries...",
 	}
 
-	selected, err := RunSelector(loader, config)
+	selected, err := RunSelector(ctx, loader, config)
 	if err != nil {
 		return nil, err
 	}
 
-	return selected.Entry, nil
+	return selected.TimeEntry, nil
 }
 
-func SelectTaskInteractively(client *api.Client, projectId int64) (*api.Task, error) {
+// SelectTaskInteractively lets the user pick a task of the project,
+// highlighting currentId when it is not zero
+func SelectTaskInteractively(ctx context.Context, client AssignmentLister, projectId int64, currentId int64) (*api.Task, error) {
 	loader := &TaskLoader{client: client, projectId: projectId}
 	config := SelectorConfig{
 		Title:      "Select a Task",
 		EmptyMsg:   "No tasks found.",
 		LoadingMsg: "Loading tasks...",
+		InitialID:  formatID(currentId),
 	}
 
-	selected, err := RunSelector(loader, config)
+	selected, err := RunSelector(ctx, loader, config)
 	if err != nil {
 		return nil, err
 	}
@@ -366,18 +478,99 @@ func SelectTaskInteractively(client *api.Client, projectId int64) (*api.Task, er
 	return &selected.Task, nil
 }
 
-func SelectProjectInteractively(client *api.Client) (*api.Project, error) {
+// SelectProjectInteractively lets the user pick an assigned project,
+// highlighting currentId when it is not zero
+func SelectProjectInteractively(ctx context.Context, client AssignmentLister, currentId int64) (*api.Project, error) {
 	loader := &ProjectLoader{client: client}
 	config := SelectorConfig{
 		Title:      "Select a Project",
 		EmptyMsg:   "No projects found.",
 		LoadingMsg: "Loading projects...",
+		InitialID:  formatID(currentId),
 	}
 
-	selected, err := RunSelector(loader, config)
+	selected, err := RunSelector(ctx, loader, config)
 	if err != nil {
 		return nil, err
 	}
 
 	return &selected.Project, nil
 }
+
+// SelectProjectMatching lets the user pick one of the projects, with the
+// list filtered on query, when query matches several of them
+func SelectProjectMatching(ctx context.Context, projects []*api.ProjectAssignment, query string) (*api.ProjectAssignment, error) {
+	items := make(SliceLoader[ProjectSelectable], len(projects))
+	for i, project := range projects {
+		items[i] = ProjectSelectable{ProjectAssignment: project}
+	}
+	config := SelectorConfig{
+		Title:    fmt.Sprintf("Several projects match %q", query),
+		EmptyMsg: "No projects found.",
+		Filter:   query,
+	}
+
+	selected, err := RunSelector(ctx, items, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return selected.ProjectAssignment, nil
+}
+
+// SelectTaskMatching lets the user pick one of the tasks, with the list
+// filtered on query, when query matches several of them
+func SelectTaskMatching(ctx context.Context, tasks []*api.TaskAssignment, query string) (*api.TaskAssignment, error) {
+	items := make(SliceLoader[TaskSelectable], len(tasks))
+	for i, task := range tasks {
+		items[i] = TaskSelectable{TaskAssignment: task}
+	}
+	config := SelectorConfig{
+		Title:    fmt.Sprintf("Several tasks match %q", query),
+		EmptyMsg: "No tasks found.",
+		Filter:   query,
+	}
+
+	selected, err := RunSelector(ctx, items, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return selected.TaskAssignment, nil
+}
+
+// AccountSelectable wraps api.Account for selection
+type AccountSelectable struct {
+	api.Account
+}
+
+func (a AccountSelectable) GetID() string {
+	return strconv.FormatInt(a.Account.ID, 10)
+}
+
+func (a AccountSelectable) GetTitle() string {
+	return a.Account.Name
+}
+
+func (a AccountSelectable) GetDescription() string {
+	return fmt.Sprintf("ID: %d", a.Account.ID)
+}
+
+// SelectAccount lets the user pick the account to log in to
+func SelectAccount(ctx context.Context, accounts []api.Account) (*api.Account, error) {
+	items := make(SliceLoader[AccountSelectable], len(accounts))
+	for i, account := range accounts {
+		items[i] = AccountSelectable{Account: account}
+	}
+	config := SelectorConfig{
+		Title:    "Select an Account",
+		EmptyMsg: "No accounts found.",
+	}
+
+	selected, err := RunSelector(ctx, items, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &selected.Account, nil
+}
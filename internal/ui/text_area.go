@@ -0,0 +1,216 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TextAreaOptions configures the multi-line text input behavior
+type TextAreaOptions struct {
+	Title        string
+	Prompt       string
+	Placeholder  string
+	Required     bool
+	CharLimit    int
+	Width        int
+	Height       int
+	ValidateFunc func(string) error
+	DefaultValue string
+}
+
+// TextAreaModel represents the multi-line text input component
+type TextAreaModel struct {
+	textArea  textarea.Model
+	options   TextAreaOptions
+	err       error
+	submitted bool
+	cancelled bool
+}
+
+// NewTextArea creates a new multi-line text input component
+func NewTextArea(options TextAreaOptions) TextAreaModel {
+	ta := textarea.New()
+
+	// Set defaults if not provided
+	if options.Prompt == "" {
+		options.Prompt = "Enter text:"
+	}
+	if options.Width == 0 {
+		options.Width = 72
+	}
+	if options.Height == 0 {
+		options.Height = 6
+	}
+
+	// Configure the text area
+	ta.Placeholder = options.Placeholder
+	ta.CharLimit = options.CharLimit
+	ta.ShowLineNumbers = false
+	ta.SetWidth(options.Width)
+	ta.SetHeight(options.Height)
+	ta.Focus()
+
+	// Set default value if provided
+	if options.DefaultValue != "" {
+		ta.SetValue(options.DefaultValue)
+	}
+
+	return TextAreaModel{
+		textArea: ta,
+		options:  options,
+	}
+}
+
+// Init initializes the text area component
+func (m TextAreaModel) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+// Update handles the text area updates. Enter inserts a new line, Ctrl+S
+// submits.
+func (m TextAreaModel) Update(msg tea.Msg) (TextAreaModel, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlS:
+			value := strings.TrimSpace(m.textArea.Value())
+
+			// Check if required and empty
+			if m.options.Required && value == "" {
+				m.err = fmt.Errorf("this field is required")
+				return m, nil
+			}
+
+			// Run custom validation if provided
+			if m.options.ValidateFunc != nil {
+				if err := m.options.ValidateFunc(value); err != nil {
+					m.err = err
+					return m, nil
+				}
+			}
+
+			// Success!
+			m.err = nil
+			m.submitted = true
+			return m, nil
+
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.cancelled = true
+			return m, nil
+		}
+
+	case error:
+		m.err = msg
+		return m, nil
+	}
+
+	m.textArea, cmd = m.textArea.Update(msg)
+
+	// Clear error when user starts typing again
+	if m.err != nil {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.Type == tea.KeyRunes {
+			m.err = nil
+		}
+	}
+
+	return m, cmd
+}
+
+// View renders the text area component
+func (m TextAreaModel) View() string {
+	var b strings.Builder
+
+	// Title
+	if m.options.Title != "" {
+		b.WriteString(textInputTitleStyle.Render(m.options.Title))
+		b.WriteString("\n\n")
+	}
+
+	if m.submitted {
+		return b.String()
+	}
+
+	// If cancelled, show cancellation message
+	if m.cancelled {
+		b.WriteString(textInputErrorStyle.Render("✗ Input cancelled"))
+		return b.String()
+	}
+
+	// Prompt
+	prompt := m.options.Prompt
+	if m.options.Required {
+		prompt += " *"
+	}
+	b.WriteString(prompt)
+	b.WriteString("\n\n")
+
+	b.WriteString(m.textArea.View())
+	b.WriteString("\n\n")
+
+	// Error message if any
+	if m.err != nil {
+		b.WriteString(textInputErrorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n\n")
+	}
+
+	// Help text
+	helpLines := []string{
+		"Press Ctrl+S to submit, Enter for a new line",
+		"Press Esc to cancel",
+	}
+
+	if m.options.CharLimit > 0 {
+		remaining := m.options.CharLimit - m.textArea.Length()
+		helpLines = append(helpLines, fmt.Sprintf("Characters remaining: %d", remaining))
+	}
+
+	for _, line := range helpLines {
+		b.WriteString(textInputHelpStyle.Render(line))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// IsSubmitted returns true if input has been submitted successfully
+func (m TextAreaModel) IsSubmitted() bool {
+	return m.submitted
+}
+
+// IsCancelled returns true if input was cancelled
+func (m TextAreaModel) IsCancelled() bool {
+	return m.cancelled
+}
+
+// GetValue returns the submitted value, or empty string if not submitted
+func (m TextAreaModel) GetValue() string {
+	if m.submitted {
+		return strings.TrimSpace(m.textArea.Value())
+	}
+	return ""
+}
+
+// reopen lets a submitted value be edited again, e.g. in the entry form
+func (m *TextAreaModel) reopen() {
+	m.submitted = false
+}
+
+// SetValue sets the current input value
+func (m *TextAreaModel) SetValue(value string) {
+	m.textArea.SetValue(value)
+}
+
+// Focus focuses the text area
+func (m *TextAreaModel) Focus() tea.Cmd {
+	return m.textArea.Focus()
+}
+
+// Blur removes focus from the text area
+func (m *TextAreaModel) Blur() {
+	m.textArea.Blur()
+}
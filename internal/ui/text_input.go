@@ -236,6 +236,12 @@ func (m *TextInputModel) Reset() {
 	m.textInput.Focus()
 }
 
+// reopen lets a submitted value be edited again, for forms where fields
+// are revisited
+func (m *TextInputModel) reopen() {
+	m.submitted = false
+}
+
 // SetValue sets the current input value
 func (m *TextInputModel) SetValue(value string) {
 	m.textInput.SetValue(value)
@@ -271,23 +277,6 @@ func TextInput(options TextInputOptions) (string, error) {
 	return "", fmt.Errorf("failed to get text input")
 }
 
-// SimpleTextInput prompts for basic text input
-func SimpleTextInput(title, prompt string) (string, error) {
-	return TextInput(TextInputOptions{
-		Title:  title,
-		Prompt: prompt,
-	})
-}
-
-// RequiredTextInput prompts for required text input
-func RequiredTextInput(title, prompt string) (string, error) {
-	return TextInput(TextInputOptions{
-		Title:    title,
-		Prompt:   prompt,
-		Required: true,
-	})
-}
-
 // PasswordInput prompts for password input
 func PasswordInput(title, prompt string) (string, error) {
 	return TextInput(TextInputOptions{
@@ -298,16 +287,6 @@ func PasswordInput(title, prompt string) (string, error) {
 	})
 }
 
-// ValidatedTextInput prompts for text input with custom validation
-func ValidatedTextInput(title, prompt string, validateFunc func(string) error) (string, error) {
-	return TextInput(TextInputOptions{
-		Title:        title,
-		Prompt:       prompt,
-		Required:     true,
-		ValidateFunc: validateFunc,
-	})
-}
-
 // textInputWrapper wraps the TextInputModel to handle quit behavior
 type textInputWrapper struct {
 	model TextInputModel